@@ -3,18 +3,31 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/danyouknowme/assessment-tax/tax"
 	"github.com/labstack/echo/v4"
+	"github.com/shopspring/decimal"
 )
 
-type SettingPersonalDeductionRequest struct {
-	Amount float64 `json:"amount" validate:"required,min=10000.0,max=100000.0"`
+// SettingDeductionRequest sets a deduction from EffectiveFrom, today when
+// omitted, until EffectiveTo inclusive, or until superseded when omitted.
+type SettingDeductionRequest struct {
+	Amount        decimal.Decimal `json:"amount" validate:"required"`
+	EffectiveFrom tax.Date        `json:"effectiveFrom"`
+	EffectiveTo   tax.Date        `json:"effectiveTo"`
 }
 
-func (s *Server) SettingPersonalDeduction(c echo.Context) error {
-	var req SettingPersonalDeductionRequest
+// SettingDeduction updates the deduction named by the :type path parameter
+// within the bounds stored alongside it.
+func (s *Server) SettingDeduction(c echo.Context) error {
+	deductionType := c.Param("type")
+
+	var req SettingDeductionRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
 	}
@@ -23,60 +36,238 @@ func (s *Server) SettingPersonalDeduction(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
 	}
 
+	today := tax.Today()
+	effectiveFrom := req.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = today
+	}
+
+	if effectiveFrom.Before(today.Time) {
+		err := errors.New("effectiveFrom must not be in the past")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if !req.EffectiveTo.IsZero() && req.EffectiveTo.Before(effectiveFrom.Time) {
+		err := errors.New("effectiveTo must not be before effectiveFrom")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	current, err := s.store.GetDeductionByType(c.Request().Context(), deductionType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("%s deduction not found", deductionType)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		err := fmt.Errorf("failed to get %s deduction", deductionType)
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	if req.Amount.LessThan(current.MinAmount) || req.Amount.GreaterThan(current.MaxAmount) {
+		err := fmt.Errorf("%s deduction must be between %s and %s", deductionType, current.MinAmount, current.MaxAmount)
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
 	deduction, err := s.store.UpdateDeductionByType(
 		c.Request().Context(),
-		"personal",
+		deductionType,
 		db.UpdateDeductionParams{
-			Amount: req.Amount,
+			Amount:        req.Amount,
+			EffectiveFrom: effectiveFrom.Time,
+			EffectiveTo:   sql.NullTime{Time: req.EffectiveTo.Time, Valid: !req.EffectiveTo.IsZero()},
+			ChangedBy:     c.Get(adminUsernameKey).(string),
+			RequestID:     c.Response().Header().Get(echo.HeaderXRequestID),
+			ClientIP:      c.RealIP(),
 		},
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			err := errors.New("personal deduction not found")
+			err := fmt.Errorf("%s deduction not found", deductionType)
 			return c.JSON(http.StatusNotFound, errorResponse(err))
 		}
 
-		err := errors.New("failed to update personal deduction")
+		err := fmt.Errorf("failed to update %s deduction", deductionType)
 		return c.JSON(http.StatusInternalServerError, errorResponse(err))
 	}
 
-	return c.JSON(http.StatusOK, map[string]float64{
-		"personalDeduction": deduction.Amount,
-	})
+	res := map[string]interface{}{
+		deductionResponseKey(deduction.Type): deduction.Amount,
+	}
+	if !req.EffectiveFrom.IsZero() {
+		res["effectiveFrom"] = effectiveFrom
+	}
+	if !req.EffectiveTo.IsZero() {
+		res["effectiveTo"] = req.EffectiveTo
+	}
+
+	return c.JSON(http.StatusOK, res)
 }
 
-type SettingKReceiptDeductionRequest struct {
-	Amount float64 `json:"amount" validate:"required,min=0.0,max=100000.0"`
+type DeductionChangeResponse struct {
+	DeductionType string          `json:"deductionType"`
+	OldAmount     decimal.Decimal `json:"oldAmount"`
+	NewAmount     decimal.Decimal `json:"newAmount"`
+	EffectiveFrom tax.Date        `json:"effectiveFrom"`
+	EffectiveTo   tax.Date        `json:"effectiveTo"`
+	ChangedBy     string          `json:"changedBy"`
+	RequestID     string          `json:"requestId"`
+	ClientIP      string          `json:"clientIp"`
+	ChangedAt     time.Time       `json:"changedAt"`
 }
 
-func (s *Server) SettingKReceiptDeduction(c echo.Context) error {
-	var req SettingKReceiptDeductionRequest
-	if err := c.Bind(&req); err != nil {
+type DeductionHistoryResponse struct {
+	History []DeductionChangeResponse `json:"history"`
+}
+
+// GetDeductionHistory lists the changes of one deduction type. The optional
+// from (inclusive) and to (exclusive) query parameters take a date or an
+// RFC 3339 timestamp.
+func (s *Server) GetDeductionHistory(c echo.Context) error {
+	deductionType := c.Param("type")
+
+	from, err := parseTimeParam(c.QueryParam("from"))
+	if err != nil {
+		err := errors.New("invalid from")
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
 	}
 
-	if err := c.Validate(req); err != nil {
+	to, err := parseTimeParam(c.QueryParam("to"))
+	if err != nil {
+		err := errors.New("invalid to")
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
 	}
 
-	deduction, err := s.store.UpdateDeductionByType(
-		c.Request().Context(),
-		"k-receipt",
-		db.UpdateDeductionParams{
-			Amount: req.Amount,
-		},
-	)
+	_, err = s.store.GetDeductionByType(c.Request().Context(), deductionType)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			err := errors.New("k-receipt deduction not found")
+			err := fmt.Errorf("%s deduction not found", deductionType)
 			return c.JSON(http.StatusNotFound, errorResponse(err))
 		}
 
-		err := errors.New("failed to update k-receipt deduction")
+		err := fmt.Errorf("failed to get %s deduction", deductionType)
 		return c.JSON(http.StatusInternalServerError, errorResponse(err))
 	}
 
-	return c.JSON(http.StatusOK, map[string]float64{
-		"kReceipt": deduction.Amount,
+	changes, err := s.store.ListDeductionChanges(c.Request().Context(), db.ListDeductionChangesParams{
+		DeductionType: deductionType,
+		From:          from,
+		To:            to,
 	})
+	if err != nil {
+		err := fmt.Errorf("failed to get %s deduction history", deductionType)
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	res := DeductionHistoryResponse{History: []DeductionChangeResponse{}}
+	for _, change := range changes {
+		res.History = append(res.History, DeductionChangeResponse{
+			DeductionType: change.DeductionType,
+			OldAmount:     change.OldAmount,
+			NewAmount:     change.NewAmount,
+			EffectiveFrom: tax.NewDate(change.EffectiveFrom),
+			EffectiveTo:   nullDate(change.EffectiveTo),
+			ChangedBy:     change.ChangedBy,
+			RequestID:     change.RequestID,
+			ClientIP:      change.ClientIP,
+			ChangedAt:     change.ChangedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func nullDate(t sql.NullTime) tax.Date {
+	if !t.Valid {
+		return tax.Date{}
+	}
+
+	return tax.NewDate(t.Time)
+}
+
+// parseTimeParam accepts an empty value, a date or an RFC 3339 timestamp.
+func parseTimeParam(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return sql.NullTime{}, err
+		}
+	}
+
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+type DeductionResponse struct {
+	Type          string           `json:"type"`
+	Amount        decimal.Decimal  `json:"amount"`
+	MinAmount     decimal.Decimal  `json:"minAmount"`
+	MaxAmount     decimal.Decimal  `json:"maxAmount"`
+	IncomePercent *decimal.Decimal `json:"incomePercent,omitempty"`
+	PerPerson     bool             `json:"perPerson,omitempty"`
+	MaxCount      int              `json:"maxCount,omitempty"`
+}
+
+type ListDeductionsResponse struct {
+	Deductions []DeductionResponse `json:"deductions"`
+}
+
+// ListDeductions returns the deductions in force on the asOf query
+// parameter, today when omitted.
+func (s *Server) ListDeductions(c echo.Context) error {
+	asOf := tax.Today()
+	if value := c.QueryParam("asOf"); value != "" {
+		t, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			err := errors.New("invalid asOf")
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		}
+		asOf = tax.NewDate(t)
+	}
+
+	deductions, err := s.store.GetAllDeductions(c.Request().Context(), asOf.Time)
+	if err != nil {
+		err := errors.New("failed to get deductions")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	res := ListDeductionsResponse{Deductions: []DeductionResponse{}}
+	for _, d := range deductions {
+		deduction := DeductionResponse{
+			Type:      d.Type,
+			Amount:    d.Amount,
+			MinAmount: d.MinAmount,
+			MaxAmount: d.MaxAmount,
+			PerPerson: d.PerPerson,
+			MaxCount:  d.MaxCount,
+		}
+		if d.IncomePercent.Valid {
+			deduction.IncomePercent = &d.IncomePercent.Decimal
+		}
+
+		res.Deductions = append(res.Deductions, deduction)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// deductionResponseKey camel-cases the deduction type, e.g. "k-receipt"
+// becomes "kReceipt". The personal deduction keeps its historical
+// "personalDeduction" key.
+func deductionResponseKey(deductionType string) string {
+	if deductionType == "personal" {
+		return "personalDeduction"
+	}
+
+	parts := strings.Split(deductionType, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+
+	return strings.Join(parts, "")
 }
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/danyouknowme/assessment-tax/tax"
+	"github.com/labstack/echo/v4"
+	"github.com/shopspring/decimal"
+)
+
+type TaxBracketRequest struct {
+	MinTotalIncome decimal.Decimal     `json:"minTotalIncome" validate:"min=0.0"`
+	MaxTotalIncome decimal.NullDecimal `json:"maxTotalIncome"`
+	TaxRate        decimal.Decimal     `json:"taxRate" validate:"min=0.0,max=100.0"`
+}
+
+type CreateTaxBracketsRequest struct {
+	TaxYear  int                 `json:"taxYear" validate:"required,min=1900,max=2100"`
+	Brackets []TaxBracketRequest `json:"brackets" validate:"required,min=1,dive"`
+}
+
+type ReplaceTaxBracketsRequest struct {
+	Brackets []TaxBracketRequest `json:"brackets" validate:"required,min=1,dive"`
+}
+
+type TaxBracketResponse struct {
+	MinTotalIncome decimal.Decimal     `json:"minTotalIncome"`
+	MaxTotalIncome decimal.NullDecimal `json:"maxTotalIncome"`
+	TaxRate        decimal.Decimal     `json:"taxRate"`
+	TaxLevel       string              `json:"taxLevel"`
+}
+
+type TaxBracketSetResponse struct {
+	TaxYear  int                  `json:"taxYear"`
+	Brackets []TaxBracketResponse `json:"brackets"`
+}
+
+func (s *Server) ListTaxBrackets(c echo.Context) error {
+	brackets, err := s.store.ListTaxBrackets(c.Request().Context())
+	if err != nil {
+		err := errors.New("failed to get tax brackets")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, newTaxBracketSetsResponse(brackets))
+}
+
+// GetTaxBrackets returns the bracket set in force for the tax year, which
+// may belong to an earlier tax year.
+func (s *Server) GetTaxBrackets(c echo.Context) error {
+	taxYear, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		err := errors.New("invalid tax year")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	brackets, err := s.store.GetTaxBracketsByYear(c.Request().Context(), taxYear)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("tax brackets for tax year %d not found", taxYear)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		err := errors.New("failed to get tax brackets")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, newTaxBracketSetResponse(brackets[0].TaxYear, brackets))
+}
+
+func (s *Server) CreateTaxBrackets(c echo.Context) error {
+	var req CreateTaxBracketsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	brackets := newTaxBrackets(req.TaxYear, req.Brackets)
+	if err := tax.ValidateTaxBrackets(brackets); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	created, err := s.store.CreateTaxBrackets(c.Request().Context(), req.TaxYear, brackets)
+	if err != nil {
+		if errors.Is(err, db.ErrTaxBracketsExist) {
+			err := fmt.Errorf("tax brackets for tax year %d already exist", req.TaxYear)
+			return c.JSON(http.StatusConflict, errorResponse(err))
+		}
+
+		err := errors.New("failed to create tax brackets")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusCreated, newTaxBracketSetResponse(req.TaxYear, created))
+}
+
+func (s *Server) ReplaceTaxBrackets(c echo.Context) error {
+	taxYear, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		err := errors.New("invalid tax year")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	var req ReplaceTaxBracketsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	brackets := newTaxBrackets(taxYear, req.Brackets)
+	if err := tax.ValidateTaxBrackets(brackets); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	replaced, err := s.store.ReplaceTaxBrackets(c.Request().Context(), taxYear, brackets)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("tax brackets for tax year %d not found", taxYear)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		err := errors.New("failed to replace tax brackets")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, newTaxBracketSetResponse(taxYear, replaced))
+}
+
+func (s *Server) DeleteTaxBrackets(c echo.Context) error {
+	taxYear, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		err := errors.New("invalid tax year")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	err = s.store.DeleteTaxBrackets(c.Request().Context(), taxYear)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("tax brackets for tax year %d not found", taxYear)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		err := errors.New("failed to delete tax brackets")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func newTaxBrackets(taxYear int, reqs []TaxBracketRequest) []db.TaxBracket {
+	brackets := make([]db.TaxBracket, 0, len(reqs))
+	for _, req := range reqs {
+		brackets = append(brackets, db.TaxBracket{
+			TaxYear:        taxYear,
+			MinTotalIncome: req.MinTotalIncome,
+			MaxTotalIncome: req.MaxTotalIncome,
+			TaxRate:        req.TaxRate,
+		})
+	}
+
+	return brackets
+}
+
+func newTaxBracketSetResponse(taxYear int, brackets []db.TaxBracket) TaxBracketSetResponse {
+	res := TaxBracketSetResponse{
+		TaxYear:  taxYear,
+		Brackets: make([]TaxBracketResponse, 0, len(brackets)),
+	}
+
+	for _, bracket := range brackets {
+		res.Brackets = append(res.Brackets, TaxBracketResponse{
+			MinTotalIncome: bracket.MinTotalIncome,
+			MaxTotalIncome: bracket.MaxTotalIncome,
+			TaxRate:        bracket.TaxRate,
+			TaxLevel:       tax.LevelLabel(bracket),
+		})
+	}
+
+	return res
+}
+
+// newTaxBracketSetsResponse groups brackets, sorted by tax year, into one
+// set per tax year.
+func newTaxBracketSetsResponse(brackets []db.TaxBracket) []TaxBracketSetResponse {
+	sets := []TaxBracketSetResponse{}
+	for start := 0; start < len(brackets); {
+		end := start
+		for end < len(brackets) && brackets[end].TaxYear == brackets[start].TaxYear {
+			end++
+		}
+
+		sets = append(sets, newTaxBracketSetResponse(brackets[start].TaxYear, brackets[start:end]))
+		start = end
+	}
+
+	return sets
+}
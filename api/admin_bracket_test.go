@@ -0,0 +1,473 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danyouknowme/assessment-tax/config"
+	"github.com/danyouknowme/assessment-tax/db"
+	mockdb "github.com/danyouknowme/assessment-tax/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+var validBracketsBody = []map[string]interface{}{
+	{"minTotalIncome": 0, "maxTotalIncome": 150000, "taxRate": 0},
+	{"minTotalIncome": 150000, "maxTotalIncome": nil, "taxRate": 10},
+}
+
+var validBrackets = []db.TaxBracket{
+	{TaxYear: 2025, MinTotalIncome: decimal.NewFromInt(0), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(150000)), TaxRate: decimal.NewFromInt(0)},
+	{TaxYear: 2025, MinTotalIncome: decimal.NewFromInt(150000), TaxRate: decimal.NewFromInt(10)},
+}
+
+func TestAdminListTaxBracketsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListTaxBrackets(gomock.Any()).
+					Times(1).
+					Return(append(defaultTaxBrackets[:2:2], validBrackets...), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `[{"taxYear":2017,"brackets":[{"minTotalIncome":0,"maxTotalIncome":150000,"taxRate":0,"taxLevel":"0-150,000"},{"minTotalIncome":150000,"maxTotalIncome":500000,"taxRate":10,"taxLevel":"150,001-500,000"}]},{"taxYear":2025,"brackets":[{"minTotalIncome":0,"maxTotalIncome":150000,"taxRate":0,"taxLevel":"0-150,000"},{"minTotalIncome":150000,"maxTotalIncome":null,"taxRate":10,"taxLevel":"150,001 ขึ้นไป"}]}]`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "OK Empty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListTaxBrackets(gomock.Any()).
+					Times(1).
+					Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `[]`, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "Failed to List Tax Brackets",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListTaxBrackets(gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/brackets", nil)
+			require.NoError(t, err)
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminGetTaxBracketsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		year          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			year: "2026",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2026)).
+					Times(1).
+					Return(validBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"taxYear":2025`)
+			},
+		},
+		{
+			name:       "Invalid Tax Year",
+			year:       "abc",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Tax Brackets",
+			year: "2000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2000)).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/brackets/"+tc.year, nil)
+			require.NoError(t, err)
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminCreateTaxBracketsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"taxYear":  2025,
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTaxBrackets(gomock.Any(), gomock.Eq(2025), gomock.Any()).
+					Times(1).
+					Return(validBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				expected := `{"taxYear":2025,"brackets":[{"minTotalIncome":0,"maxTotalIncome":150000,"taxRate":0,"taxLevel":"0-150,000"},{"minTotalIncome":150000,"maxTotalIncome":null,"taxRate":10,"taxLevel":"150,001 ขึ้นไป"}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "Invalid Body(Missing Tax Year)",
+			body: map[string]interface{}{
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Rate over 100)",
+			body: map[string]interface{}{
+				"taxYear": 2025,
+				"brackets": []map[string]interface{}{
+					{"minTotalIncome": 0, "taxRate": 120},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Gap between brackets)",
+			body: map[string]interface{}{
+				"taxYear": 2025,
+				"brackets": []map[string]interface{}{
+					{"minTotalIncome": 0, "maxTotalIncome": 150000, "taxRate": 0},
+					{"minTotalIncome": 200000, "taxRate": 10},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "gap between tax bracket 1 and tax bracket 2")
+			},
+		},
+		{
+			name: "Tax Year Already Exists",
+			body: map[string]interface{}{
+				"taxYear":  2025,
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTaxBrackets(gomock.Any(), gomock.Eq(2025), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrTaxBracketsExist)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Create Tax Brackets",
+			body: map[string]interface{}{
+				"taxYear":  2025,
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTaxBrackets(gomock.Any(), gomock.Eq(2025), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/brackets", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminReplaceTaxBracketsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		year          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			year: "2025",
+			body: map[string]interface{}{
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReplaceTaxBrackets(gomock.Any(), gomock.Eq(2025), gomock.Any()).
+					Times(1).
+					Return(validBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Tax Year",
+			year: "abc",
+			body: map[string]interface{}{
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Overlapping brackets)",
+			year: "2025",
+			body: map[string]interface{}{
+				"brackets": []map[string]interface{}{
+					{"minTotalIncome": 0, "maxTotalIncome": 150000, "taxRate": 0},
+					{"minTotalIncome": 100000, "taxRate": 10},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Tax Brackets",
+			year: "2025",
+			body: map[string]interface{}{
+				"brackets": validBracketsBody,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReplaceTaxBrackets(gomock.Any(), gomock.Eq(2025), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/admin/brackets/"+tc.year, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminDeleteTaxBracketsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		year          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			year: "2025",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteTaxBrackets(gomock.Any(), gomock.Eq(2025)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Tax Brackets",
+			year: "2025",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteTaxBrackets(gomock.Any(), gomock.Eq(2025)).
+					Times(1).
+					Return(sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Delete Tax Brackets",
+			year: "2025",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteTaxBrackets(gomock.Any(), gomock.Eq(2025)).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, "/admin/brackets/"+tc.year, nil)
+			require.NoError(t, err)
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -64,3 +64,84 @@ func TestIntegrationAdminSettingKReceiptDeduction(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 	require.Equal(t, expected, strings.TrimSpace(string(byteBody)))
 }
+
+func TestIntegrationAdminListTaxBrackets(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/brackets", serverPort), nil)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(testServer.config.AdminUsername, testServer.config.AdminPassword)
+
+	client := http.Client{}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	byteBody, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	expected := `[{"taxYear":2017,"brackets":[{"minTotalIncome":0,"maxTotalIncome":150000,"taxRate":0,"taxLevel":"0-150,000"},{"minTotalIncome":150000,"maxTotalIncome":500000,"taxRate":10,"taxLevel":"150,001-500,000"},{"minTotalIncome":500000,"maxTotalIncome":1000000,"taxRate":15,"taxLevel":"500,001-1,000,000"},{"minTotalIncome":1000000,"maxTotalIncome":2000000,"taxRate":20,"taxLevel":"1,000,001-2,000,000"},{"minTotalIncome":2000000,"maxTotalIncome":null,"taxRate":35,"taxLevel":"2,000,001 ขึ้นไป"}]}]`
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, expected, strings.TrimSpace(string(byteBody)))
+}
+
+func TestIntegrationAdminSettingDonationDeduction(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	reqBody := `{"amount": 80000.0}`
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://localhost:%d/admin/deductions/donation", serverPort), strings.NewReader(reqBody))
+	require.NoError(t, err)
+
+	req.SetBasicAuth(testServer.config.AdminUsername, testServer.config.AdminPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := http.Client{}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	byteBody, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	expected := `{"donation":80000}`
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, expected, strings.TrimSpace(string(byteBody)))
+}
+
+func TestIntegrationAdminDeductionHistory(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	client := http.Client{}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/admin/deductions/personal", serverPort), strings.NewReader(`{"amount":70000.0}`))
+	require.NoError(t, err)
+
+	req.SetBasicAuth(testServer.config.AdminUsername, testServer.config.AdminPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/admin/deductions/personal/history", serverPort), nil)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(testServer.config.AdminUsername, testServer.config.AdminPassword)
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+
+	byteBody, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, string(byteBody), `"oldAmount":60000,"newAmount":70000,"changedBy":"`+testServer.config.AdminUsername+`"`)
+}
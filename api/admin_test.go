@@ -2,31 +2,74 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/danyouknowme/assessment-tax/config"
 	"github.com/danyouknowme/assessment-tax/db"
 	mockdb "github.com/danyouknowme/assessment-tax/db/mock"
+	"github.com/danyouknowme/assessment-tax/tax"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
-func TestAdminSetPersonalDeductionAPI(t *testing.T) {
+var (
+	personalDeduction = db.Deduction{Type: "personal", Amount: decimal.NewFromInt(60000), MinAmount: decimal.NewFromInt(10000), MaxAmount: decimal.NewFromInt(100000)}
+	donationDeduction = db.Deduction{Type: "donation", Amount: decimal.NewFromInt(100000), MinAmount: decimal.NewFromInt(0), MaxAmount: decimal.NewFromInt(100000)}
+	kReceiptDeduction = db.Deduction{Type: "k-receipt", Amount: decimal.NewFromInt(50000), MinAmount: decimal.NewFromInt(0), MaxAmount: decimal.NewFromInt(100000)}
+)
+
+type eqUpdateDeductionParamsMatcher struct {
+	amount    decimal.Decimal
+	changedBy string
+}
+
+// eqUpdateDeductionParams matches the amount and admin of an update taking
+// effect today, and that the change carries the request ID generated for it.
+func eqUpdateDeductionParams(amount decimal.Decimal, changedBy string) gomock.Matcher {
+	return eqUpdateDeductionParamsMatcher{amount: amount, changedBy: changedBy}
+}
+
+func (e eqUpdateDeductionParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateDeductionParams)
+	if !ok {
+		return false
+	}
+
+	return arg.Amount.Equal(e.amount) &&
+		arg.EffectiveFrom.Equal(tax.Today().Time) &&
+		!arg.EffectiveTo.Valid &&
+		arg.ChangedBy == e.changedBy &&
+		arg.RequestID != ""
+}
+
+func (e eqUpdateDeductionParamsMatcher) String() string {
+	return fmt.Sprintf("matches amount %v changed by %s", e.amount, e.changedBy)
+}
+
+func TestAdminSettingDeductionAPI(t *testing.T) {
 	testCases := []struct {
 		name          string
-		body          map[string]float64
+		method        string
+		deductionType string
+		body          map[string]interface{}
 		setupAuth     func(request *http.Request)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "OK",
-			body: map[string]float64{
+			name:          "OK Personal",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
 				"amount": 70000.0,
 			},
 			setupAuth: func(request *http.Request) {
@@ -34,9 +77,13 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateDeductionByType(gomock.Any(), gomock.Any(), db.UpdateDeductionParams{Amount: 70000.0}).
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+				store.EXPECT().
+					UpdateDeductionByType(gomock.Any(), gomock.Eq("personal"), eqUpdateDeductionParams(decimal.NewFromInt(70000), "adminTest")).
 					Times(1).
-					Return(&db.Deduction{Type: "personal", Amount: 70000.0}, nil)
+					Return(&db.Deduction{Type: "personal", Amount: decimal.NewFromInt(70000)}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -46,8 +93,101 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Invalid Body(Missing Amount)",
-			body: map[string]float64{},
+			name:          "OK K-Receipt",
+			method:        http.MethodPost,
+			deductionType: "k-receipt",
+			body: map[string]interface{}{
+				"amount": 70000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("k-receipt")).
+					Times(1).
+					Return(&kReceiptDeduction, nil)
+				store.EXPECT().
+					UpdateDeductionByType(gomock.Any(), gomock.Eq("k-receipt"), eqUpdateDeductionParams(decimal.NewFromInt(70000), "adminTest")).
+					Times(1).
+					Return(&db.Deduction{Type: "k-receipt", Amount: decimal.NewFromInt(70000)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"kReceipt":70000}`
+				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:          "OK Donation With PUT",
+			method:        http.MethodPut,
+			deductionType: "donation",
+			body: map[string]interface{}{
+				"amount": 80000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("donation")).
+					Times(1).
+					Return(&donationDeduction, nil)
+				store.EXPECT().
+					UpdateDeductionByType(gomock.Any(), gomock.Eq("donation"), eqUpdateDeductionParams(decimal.NewFromInt(80000), "adminTest")).
+					Times(1).
+					Return(&db.Deduction{Type: "donation", Amount: decimal.NewFromInt(80000)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"donation":80000}`
+				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:          "OK Scheduled",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount":        80000.0,
+				"effectiveFrom": "2100-01-01",
+				"effectiveTo":   "2100-12-31",
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+				store.EXPECT().
+					UpdateDeductionByType(gomock.Any(), gomock.Eq("personal"), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ string, arg db.UpdateDeductionParams) (*db.Deduction, error) {
+						require.Equal(t, "2100-01-01", arg.EffectiveFrom.Format(time.DateOnly))
+						require.True(t, arg.EffectiveTo.Valid)
+						require.Equal(t, "2100-12-31", arg.EffectiveTo.Time.Format(time.DateOnly))
+						return &db.Deduction{Type: "personal", Amount: arg.Amount}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"effectiveFrom":"2100-01-01","effectiveTo":"2100-12-31","personalDeduction":80000}`
+				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:          "Invalid Body(Effective From In The Past)",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount":        80000.0,
+				"effectiveFrom": "2000-01-01",
+			},
 			setupAuth: func(request *http.Request) {
 				request.SetBasicAuth("adminTest", "test!")
 			},
@@ -57,9 +197,13 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Invalid Body(Amount < 10000)",
-			body: map[string]float64{
-				"amount": 5000.0,
+			name:          "Invalid Body(Effective To Before Effective From)",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount":        80000.0,
+				"effectiveFrom": "2100-06-01",
+				"effectiveTo":   "2100-01-01",
 			},
 			setupAuth: func(request *http.Request) {
 				request.SetBasicAuth("adminTest", "test!")
@@ -70,8 +214,102 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Not Found Personal Deduction",
-			body: map[string]float64{
+			name:          "Invalid Body(Malformed Effective From)",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount":        80000.0,
+				"effectiveFrom": "01/01/2100",
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:          "Invalid Body(Missing Amount)",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body:          map[string]interface{}{},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:          "Invalid Body(Personal Amount < 10000)",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount": 5000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				expected := `{"error":"personal deduction must be between 10000 and 100000"}`
+				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:          "Invalid Body(Negative K-Receipt Amount)",
+			method:        http.MethodPost,
+			deductionType: "k-receipt",
+			body: map[string]interface{}{
+				"amount": -70000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("k-receipt")).
+					Times(1).
+					Return(&kReceiptDeduction, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:          "Invalid Body(Donation Amount > 100000)",
+			method:        http.MethodPost,
+			deductionType: "donation",
+			body: map[string]interface{}{
+				"amount": 150000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("donation")).
+					Times(1).
+					Return(&donationDeduction, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:          "Not Found Deduction Type",
+			method:        http.MethodPost,
+			deductionType: "unknown",
+			body: map[string]interface{}{
 				"amount": 70000.0,
 			},
 			setupAuth: func(request *http.Request) {
@@ -79,7 +317,7 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateDeductionByType(gomock.Any(), gomock.Any(), db.UpdateDeductionParams{Amount: 70000.0}).
+					GetDeductionByType(gomock.Any(), gomock.Eq("unknown")).
 					Times(1).
 					Return(nil, sql.ErrNoRows)
 			},
@@ -88,8 +326,10 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Failed to Update Personal Deduction",
-			body: map[string]float64{
+			name:          "Failed to Get Deduction",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
 				"amount": 70000.0,
 			},
 			setupAuth: func(request *http.Request) {
@@ -97,7 +337,7 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateDeductionByType(gomock.Any(), gomock.Any(), db.UpdateDeductionParams{Amount: 70000.0}).
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
 					Times(1).
 					Return(nil, sql.ErrConnDone)
 			},
@@ -105,6 +345,67 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:          "Not Found Personal Deduction On Update",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount": 70000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+				store.EXPECT().
+					UpdateDeductionByType(gomock.Any(), gomock.Any(), eqUpdateDeductionParams(decimal.NewFromInt(70000), "adminTest")).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:          "Failed to Update Personal Deduction",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount": 70000.0,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("adminTest", "test!")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+				store.EXPECT().
+					UpdateDeductionByType(gomock.Any(), gomock.Any(), eqUpdateDeductionParams(decimal.NewFromInt(70000), "adminTest")).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:          "Unauthorized",
+			method:        http.MethodPost,
+			deductionType: "personal",
+			body: map[string]interface{}{
+				"amount": 70000.0,
+			},
+			setupAuth:  func(request *http.Request) {},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -126,8 +427,8 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 			data, err := json.Marshal(tc.body)
 			require.NoError(t, err)
 
-			url := "/admin/deductions/personal"
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			url := "/admin/deductions/" + tc.deductionType
+			request, err := http.NewRequest(tc.method, url, bytes.NewReader(data))
 			require.NoError(t, err)
 
 			request.Header.Set("Content-Type", "application/json")
@@ -139,88 +440,204 @@ func TestAdminSetPersonalDeductionAPI(t *testing.T) {
 	}
 }
 
-func TestAdminSetKReceiptDeductionAPI(t *testing.T) {
+func TestAdminGetDeductionHistoryAPI(t *testing.T) {
+	changedAt := time.Date(2024, 3, 3, 9, 30, 0, 0, time.UTC)
+
 	testCases := []struct {
 		name          string
-		body          map[string]float64
-		setupAuth     func(request *http.Request)
+		deductionType string
+		query         string
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "OK",
-			body: map[string]float64{
-				"amount": 70000.0,
-			},
-			setupAuth: func(request *http.Request) {
-				request.SetBasicAuth("adminTest", "test!")
-			},
+			name:          "OK",
+			deductionType: "personal",
+			query:         "?from=2024-03-01&to=2024-03-04T00:00:00Z",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateDeductionByType(gomock.Any(), gomock.Any(), db.UpdateDeductionParams{Amount: 70000.0}).
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+				store.EXPECT().
+					ListDeductionChanges(gomock.Any(), gomock.Eq(db.ListDeductionChangesParams{
+						DeductionType: "personal",
+						From:          sql.NullTime{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+						To:            sql.NullTime{Time: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Valid: true},
+					})).
 					Times(1).
-					Return(&db.Deduction{Type: "k-receipt", Amount: 70000.0}, nil)
+					Return([]db.DeductionChange{
+						{
+							DeductionType: "personal",
+							OldAmount:     decimal.NewFromInt(60000),
+							NewAmount:     decimal.NewFromInt(70000),
+							EffectiveFrom: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+							ChangedBy:     "adminTax",
+							RequestID:     "req-1",
+							ClientIP:      "10.0.0.1",
+							ChangedAt:     changedAt,
+						},
+					}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 
-				expected := `{"kReceipt":70000}`
-				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+				expected := `{"history":[{"deductionType":"personal","oldAmount":60000,"newAmount":70000,"effectiveFrom":"2024-03-03","effectiveTo":null,"changedBy":"adminTax","requestId":"req-1","clientIp":"10.0.0.1","changedAt":"2024-03-03T09:30:00Z"}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
 			},
 		},
 		{
-			name: "Invalid Body(Missing Amount)",
-			body: map[string]float64{},
-			setupAuth: func(request *http.Request) {
-				request.SetBasicAuth("adminTest", "test!")
+			name:          "OK Without Range",
+			deductionType: "donation",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("donation")).
+					Times(1).
+					Return(&donationDeduction, nil)
+				store.EXPECT().
+					ListDeductionChanges(gomock.Any(), gomock.Eq(db.ListDeductionChangesParams{DeductionType: "donation"})).
+					Times(1).
+					Return(nil, nil)
 			},
-			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `{"history":[]}`, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:          "Invalid From",
+			deductionType: "personal",
+			query:         "?from=yesterday",
+			buildStubs:    func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid Body(Negative Amount)",
-			body: map[string]float64{
-				"amount": -70000.0,
+			name:          "Not Found Deduction Type",
+			deductionType: "unknown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("unknown")).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
 			},
-			setupAuth: func(request *http.Request) {
-				request.SetBasicAuth("adminTest", "test!")
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:          "Failed to Get History",
+			deductionType: "personal",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeductionByType(gomock.Any(), gomock.Eq("personal")).
+					Times(1).
+					Return(&personalDeduction, nil)
+				store.EXPECT().
+					ListDeductionChanges(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
 			},
-			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/admin/deductions/" + tc.deductionType + "/history" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminListDeductionsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
 		{
-			name: "Not Found K-Receipt Deduction",
-			body: map[string]float64{
-				"amount": 70000.0,
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{personalDeduction, donationDeduction, kReceiptDeduction}, nil)
 			},
-			setupAuth: func(request *http.Request) {
-				request.SetBasicAuth("adminTest", "test!")
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"deductions":[{"type":"personal","amount":60000,"minAmount":10000,"maxAmount":100000},{"type":"donation","amount":100000,"minAmount":0,"maxAmount":100000},{"type":"k-receipt","amount":50000,"minAmount":0,"maxAmount":100000}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
 			},
+		},
+		{
+			name: "OK with Allowance Rules",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateDeductionByType(gomock.Any(), gomock.Any(), db.UpdateDeductionParams{Amount: 70000.0}).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(nil, sql.ErrNoRows)
+					Return([]db.Deduction{
+						{Type: "parent", Amount: decimal.NewFromInt(30000), MaxAmount: decimal.NewFromInt(100000), PerPerson: true, MaxCount: 4},
+						{Type: "ssf", Amount: decimal.NewFromInt(200000), MaxAmount: decimal.NewFromInt(500000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(30))},
+					}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"deductions":[{"type":"parent","amount":30000,"minAmount":0,"maxAmount":100000,"perPerson":true,"maxCount":4},{"type":"ssf","amount":200000,"minAmount":0,"maxAmount":500000,"incomePercent":30}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
 			},
 		},
 		{
-			name: "Failed to Update K-Receipt Deduction",
-			body: map[string]float64{
-				"amount": 70000.0,
+			name:  "OK As Of",
+			query: "?asOf=2100-01-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Eq(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))).
+					Times(1).
+					Return([]db.Deduction{personalDeduction}, nil)
 			},
-			setupAuth: func(request *http.Request) {
-				request.SetBasicAuth("adminTest", "test!")
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "Invalid As Of",
+			query:      "?asOf=tomorrow",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
+		},
+		{
+			name: "Failed to Get Deductions",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateDeductionByType(gomock.Any(), gomock.Any(), db.UpdateDeductionParams{Amount: 70000.0}).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil, sql.ErrConnDone)
 			},
@@ -246,18 +663,25 @@ func TestAdminSetKReceiptDeductionAPI(t *testing.T) {
 			server := NewServer(&cfg, store)
 			recorder := httptest.NewRecorder()
 
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
-			url := "/admin/deductions/k-receipt"
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request, err := http.NewRequest(http.MethodGet, "/admin/deductions"+tc.query, nil)
 			require.NoError(t, err)
 
-			request.Header.Set("Content-Type", "application/json")
-
-			tc.setupAuth(request)
+			request.SetBasicAuth("adminTest", "test!")
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
+
+func TestDeductionResponseKey(t *testing.T) {
+	testCases := map[string]string{
+		"personal":       "personalDeduction",
+		"donation":       "donation",
+		"k-receipt":      "kReceipt",
+		"life-insurance": "lifeInsurance",
+	}
+
+	for deductionType, expected := range testCases {
+		require.Equal(t, expected, deductionResponseKey(deductionType))
+	}
+}
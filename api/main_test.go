@@ -0,0 +1,15 @@
+package api
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMain(m *testing.M) {
+	// Serve money as JSON numbers, as main does.
+	decimal.MarshalJSONWithoutQuotes = true
+
+	os.Exit(m.Run())
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// adminUsernameKey is the echo context key basicAuth stores the
+// authenticated admin username under.
+const adminUsernameKey = "adminUsername"
+
+// maxRequestIDLength bounds the X-Request-ID a client may pass on.
+const maxRequestIDLength = 64
+
 func (s *Server) basicAuth(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		u, p, ok := c.Request().BasicAuth()
@@ -21,6 +28,8 @@ func (s *Server) basicAuth(next echo.HandlerFunc) echo.HandlerFunc {
 			return c.JSON(http.StatusUnauthorized, errorResponse(err))
 		}
 
+		c.Set(adminUsernameKey, u)
+
 		return next(c)
 	}
 }
@@ -41,3 +50,32 @@ func (s *Server) acceptCSVExtension(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// dropInvalidRequestID removes an incoming X-Request-ID that is too long or
+// holds anything but letters, digits, '.', '_' and '-', so that the RequestID
+// middleware generates one instead of echoing it into the audit log.
+func dropInvalidRequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := c.Request().Header
+		if id := header.Get(echo.HeaderXRequestID); id != "" && !isValidRequestID(id) {
+			header.Del(echo.HeaderXRequestID)
+		}
+
+		return next(c)
+	}
+}
+
+func isValidRequestID(id string) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		valid := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-'
+		if !valid {
+			return false
+		}
+	}
+
+	return true
+}
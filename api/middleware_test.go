@@ -11,6 +11,7 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/danyouknowme/assessment-tax/config"
@@ -154,3 +155,61 @@ func createFormFile(w *multipart.Writer, filename string) (io.Writer, error) {
 	h.Set("Content-Type", mime.TypeByExtension(filepath.Ext(filename)))
 	return w.CreatePart(h)
 }
+
+func TestDropInvalidRequestID(t *testing.T) {
+	testCases := []struct {
+		name          string
+		requestID     string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK Kept",
+			requestID: "req-123_abc.1",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "req-123_abc.1", recorder.Header().Get(echo.HeaderXRequestID))
+			},
+		},
+		{
+			name:      "Too Long",
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				id := recorder.Header().Get(echo.HeaderXRequestID)
+				require.NotEmpty(t, id)
+				require.NotEqual(t, strings.Repeat("a", maxRequestIDLength+1), id)
+			},
+		},
+		{
+			name:      "Invalid Characters",
+			requestID: "id'; DROP TABLE x",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				id := recorder.Header().Get(echo.HeaderXRequestID)
+				require.NotEmpty(t, id)
+				require.NotEqual(t, "id'; DROP TABLE x", id)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(&config.Config{}, nil)
+
+			var realIP string
+			server.router.GET("/request-id", func(c echo.Context) error {
+				realIP = c.RealIP()
+				return c.String(http.StatusOK, "OK")
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/request-id", nil)
+			require.NoError(t, err)
+
+			request.RemoteAddr = "192.0.2.1:1234"
+			request.Header.Set(echo.HeaderXRequestID, tc.requestID)
+			request.Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, "192.0.2.1", realIP)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
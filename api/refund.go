@@ -0,0 +1,261 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/danyouknowme/assessment-tax/tax"
+	"github.com/labstack/echo/v4"
+	"github.com/shopspring/decimal"
+)
+
+// ClaimRefundRequest claims the refund of Calculation for a taxpayer whose
+// return is filed on FilingDate, today when omitted. The tax year of
+// Calculation defaults to the year before FilingDate.
+type ClaimRefundRequest struct {
+	TaxpayerID  string                 `json:"taxpayerId" validate:"required,max=255"`
+	FilingDate  tax.Date               `json:"filingDate"`
+	Calculation tax.CalculationRequest `json:"calculation"`
+}
+
+// UpdateRefundStatusRequest moves a claim on to Status. Claims are never
+// moved back to submitted. PaidAt, today when omitted, may not be in the
+// future.
+type UpdateRefundStatusRequest struct {
+	Status string   `json:"status" validate:"required,oneof=under_review paid"`
+	PaidAt tax.Date `json:"paidAt"`
+}
+
+// RefundClaimResponse carries the interest owed on a late refund: fixed
+// when it was paid, otherwise accrued until today. MonthsLate counts the
+// months or parts of a month after DueDate.
+type RefundClaimResponse struct {
+	ID         int             `json:"id"`
+	TaxpayerID string          `json:"taxpayerId"`
+	TaxYear    int             `json:"taxYear"`
+	Amount     decimal.Decimal `json:"amount"`
+	FiledAt    tax.Date        `json:"filedAt"`
+	Status     string          `json:"status"`
+	DueDate    tax.Date        `json:"dueDate"`
+	MonthsLate int             `json:"monthsLate"`
+	Interest   decimal.Decimal `json:"interest"`
+	PaidAt     tax.Date        `json:"paidAt"`
+	UpdatedBy  string          `json:"updatedBy"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// ClaimRefund calculates the tax of the request and records a claim for
+// its refund, one per taxpayer and tax year. Claims are recorded by an admin
+// since nothing proves the caller is the taxpayer.
+func (s *Server) ClaimRefund(c echo.Context) error {
+	var req ClaimRefundRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	filedOn := req.FilingDate
+	if filedOn.IsZero() {
+		filedOn = tax.Today()
+	}
+
+	calculation := req.Calculation.FiledOn(filedOn)
+	if filedOn.Year() <= calculation.Year() {
+		err := errors.New("filing date must be after the tax year")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, calculation.AsOfDate(), calculation.Year())
+	if err != nil {
+		return c.JSON(status, errorResponse(err))
+	}
+
+	result := tax.Calculate(taxBrackets, defaultDeductions, calculation)
+	if !result.TaxRefund.IsPositive() {
+		err := errors.New("no refund is due")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	claim, err := s.store.CreateRefundClaim(c.Request().Context(), db.CreateRefundClaimParams{
+		TaxpayerID: req.TaxpayerID,
+		TaxYear:    calculation.Year(),
+		Amount:     result.TaxRefund,
+		FiledAt:    filedOn.Time,
+		Status:     string(tax.RefundSubmitted),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRefundClaimExists) {
+			err := fmt.Errorf("refund of tax year %d already claimed by %s", calculation.Year(), req.TaxpayerID)
+			return c.JSON(http.StatusConflict, errorResponse(err))
+		}
+
+		err := errors.New("failed to create refund claim")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusCreated, newRefundClaimResponse(*claim, tax.Today()))
+}
+
+// ListRefundClaims returns every refund claim, or those in the status
+// given by the status query parameter.
+func (s *Server) ListRefundClaims(c echo.Context) error {
+	var arg db.ListRefundClaimsParams
+	if status := c.QueryParam("status"); status != "" {
+		if !tax.IsRefundStatus(status) {
+			err := errors.New("invalid status")
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		}
+
+		arg.Status = sql.NullString{String: status, Valid: true}
+	}
+
+	claims, err := s.store.ListRefundClaims(c.Request().Context(), arg)
+	if err != nil {
+		err := errors.New("failed to get refund claims")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	today := tax.Today()
+	res := make([]RefundClaimResponse, 0, len(claims))
+	for _, claim := range claims {
+		res = append(res, newRefundClaimResponse(claim, today))
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetRefundClaim returns a refund claim with the interest owed on it.
+func (s *Server) GetRefundClaim(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		err := errors.New("invalid refund claim id")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	claim, err := s.store.GetRefundClaim(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("refund claim %d not found", id)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		err := errors.New("failed to get refund claim")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, newRefundClaimResponse(*claim, tax.Today()))
+}
+
+// UpdateRefundStatus moves a refund claim on to its next status. Paying a
+// claim, on paidAt or today, fixes the interest owed on it.
+func (s *Server) UpdateRefundStatus(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		err := errors.New("invalid refund claim id")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	var req UpdateRefundStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if req.PaidAt.After(tax.Today().Time) {
+		err := errors.New("paidAt must not be in the future")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	claim, err := s.store.GetRefundClaim(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("refund claim %d not found", id)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		err := errors.New("failed to get refund claim")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	status := tax.RefundStatus(req.Status)
+	if err := tax.MoveRefund(tax.RefundStatus(claim.Status), status); err != nil {
+		return c.JSON(http.StatusConflict, errorResponse(err))
+	}
+
+	arg := db.UpdateRefundClaimStatusParams{
+		FromStatus: claim.Status,
+		Status:     req.Status,
+		Interest:   decimal.Zero,
+		UpdatedBy:  c.Get(adminUsernameKey).(string),
+	}
+	if status == tax.RefundPaid {
+		paidOn := req.PaidAt
+		if paidOn.IsZero() {
+			paidOn = tax.Today()
+		}
+
+		if paidOn.Before(claim.FiledAt) {
+			err := errors.New("paidAt must not be before the filing date")
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		}
+
+		_, arg.Interest = tax.RefundInterest(claim.Amount, claim.TaxYear, tax.NewDate(claim.FiledAt), paidOn)
+		arg.PaidAt = sql.NullTime{Time: paidOn.Time, Valid: true}
+	}
+
+	updated, err := s.store.UpdateRefundClaimStatus(c.Request().Context(), id, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("refund claim %d not found", id)
+			return c.JSON(http.StatusNotFound, errorResponse(err))
+		}
+
+		if errors.Is(err, db.ErrRefundClaimStatusChanged) {
+			err := fmt.Errorf("refund claim %d was updated concurrently", id)
+			return c.JSON(http.StatusConflict, errorResponse(err))
+		}
+
+		err := errors.New("failed to update refund claim")
+		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, newRefundClaimResponse(*updated, tax.Today()))
+}
+
+func newRefundClaimResponse(claim db.RefundClaim, today tax.Date) RefundClaimResponse {
+	filedOn := tax.NewDate(claim.FiledAt)
+	res := RefundClaimResponse{
+		ID:         claim.ID,
+		TaxpayerID: claim.TaxpayerID,
+		TaxYear:    claim.TaxYear,
+		Amount:     claim.Amount,
+		FiledAt:    filedOn,
+		Status:     claim.Status,
+		DueDate:    tax.RefundDueDate(claim.TaxYear, filedOn),
+		Interest:   claim.Interest,
+		UpdatedBy:  claim.UpdatedBy,
+		CreatedAt:  claim.CreatedAt,
+		UpdatedAt:  claim.UpdatedAt,
+	}
+
+	if claim.PaidAt.Valid {
+		res.PaidAt = tax.NewDate(claim.PaidAt.Time)
+		res.MonthsLate, _ = tax.RefundInterest(claim.Amount, claim.TaxYear, filedOn, res.PaidAt)
+	} else {
+		res.MonthsLate, res.Interest = tax.RefundInterest(claim.Amount, claim.TaxYear, filedOn, today)
+	}
+
+	return res
+}
@@ -0,0 +1,698 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danyouknowme/assessment-tax/config"
+	"github.com/danyouknowme/assessment-tax/db"
+	mockdb "github.com/danyouknowme/assessment-tax/db/mock"
+	"github.com/danyouknowme/assessment-tax/tax"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func newRefundClaim(status string) *db.RefundClaim {
+	return &db.RefundClaim{
+		ID:         1,
+		TaxpayerID: "T-001",
+		TaxYear:    2024,
+		Amount:     decimal.NewFromInt(10000),
+		FiledAt:    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Status:     status,
+		Interest:   decimal.Zero,
+	}
+}
+
+type eqCreateRefundClaimParamsMatcher struct {
+	arg db.CreateRefundClaimParams
+}
+
+// eqCreateRefundClaimParams matches the claim to create, comparing its
+// amount by value.
+func eqCreateRefundClaimParams(arg db.CreateRefundClaimParams) gomock.Matcher {
+	return eqCreateRefundClaimParamsMatcher{arg: arg}
+}
+
+func (e eqCreateRefundClaimParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateRefundClaimParams)
+	if !ok {
+		return false
+	}
+
+	return arg.TaxpayerID == e.arg.TaxpayerID &&
+		arg.TaxYear == e.arg.TaxYear &&
+		arg.Amount.Equal(e.arg.Amount) &&
+		arg.FiledAt.Equal(e.arg.FiledAt) &&
+		arg.Status == e.arg.Status
+}
+
+func (e eqCreateRefundClaimParamsMatcher) String() string {
+	return fmt.Sprintf("matches %v", e.arg)
+}
+
+type eqUpdateRefundClaimStatusParamsMatcher struct {
+	arg db.UpdateRefundClaimStatusParams
+}
+
+// eqUpdateRefundClaimStatusParams matches the status update, comparing its
+// interest by value.
+func eqUpdateRefundClaimStatusParams(arg db.UpdateRefundClaimStatusParams) gomock.Matcher {
+	return eqUpdateRefundClaimStatusParamsMatcher{arg: arg}
+}
+
+func (e eqUpdateRefundClaimStatusParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateRefundClaimStatusParams)
+	if !ok {
+		return false
+	}
+
+	return arg.FromStatus == e.arg.FromStatus &&
+		arg.Status == e.arg.Status &&
+		arg.PaidAt.Valid == e.arg.PaidAt.Valid &&
+		arg.PaidAt.Time.Equal(e.arg.PaidAt.Time) &&
+		arg.Interest.Equal(e.arg.Interest) &&
+		arg.UpdatedBy == e.arg.UpdatedBy
+}
+
+func (e eqUpdateRefundClaimStatusParamsMatcher) String() string {
+	return fmt.Sprintf("matches %v", e.arg)
+}
+
+func TestAdminClaimRefundAPI(t *testing.T) {
+	calculation := map[string]interface{}{
+		"totalIncome": 500000.0,
+		"wht":         30000.0,
+		"taxYear":     2024,
+		"allowances":  []map[string]interface{}{},
+	}
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"taxpayerId":  "T-001",
+				"filingDate":  "2025-02-01",
+				"calculation": calculation,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2024)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+				store.EXPECT().
+					CreateRefundClaim(gomock.Any(), eqCreateRefundClaimParams(db.CreateRefundClaimParams{
+						TaxpayerID: "T-001",
+						TaxYear:    2024,
+						Amount:     decimal.NewFromInt(1000),
+						FiledAt:    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+						Status:     "submitted",
+					})).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateRefundClaimParams) (*db.RefundClaim, error) {
+						claim := newRefundClaim("submitted")
+						claim.Amount = arg.Amount
+						return claim, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res RefundClaimResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, 1, res.ID)
+				require.Equal(t, "1000", res.Amount.String())
+				require.Equal(t, "submitted", res.Status)
+				require.Equal(t, "2025-06-30", res.DueDate.String())
+			},
+		},
+		{
+			name: "OK Default Tax Year",
+			body: map[string]interface{}{
+				"taxpayerId": "T-001",
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"wht":         30000.0,
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(tax.Today().Year()-1)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+				store.EXPECT().
+					CreateRefundClaim(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateRefundClaimParams) (*db.RefundClaim, error) {
+						require.Equal(t, tax.Today().Year()-1, arg.TaxYear)
+						require.True(t, arg.FiledAt.Equal(tax.Today().Time))
+						return newRefundClaim("submitted"), nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "No Refund Due",
+			body: map[string]interface{}{
+				"taxpayerId": "T-001",
+				"filingDate": "2025-02-01",
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"taxYear":     2024,
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+				store.EXPECT().
+					CreateRefundClaim(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Missing Taxpayer ID)",
+			body: map[string]interface{}{
+				"filingDate":  "2025-02-01",
+				"calculation": calculation,
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Filing Date Within Tax Year)",
+			body: map[string]interface{}{
+				"taxpayerId":  "T-001",
+				"filingDate":  "2024-12-01",
+				"calculation": calculation,
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Already Claimed",
+			body: map[string]interface{}{
+				"taxpayerId":  "T-001",
+				"filingDate":  "2025-02-01",
+				"calculation": calculation,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+				store.EXPECT().
+					CreateRefundClaim(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrRefundClaimExists)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Create Refund Claim",
+			body: map[string]interface{}{
+				"taxpayerId":  "T-001",
+				"filingDate":  "2025-02-01",
+				"calculation": calculation,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+				store.EXPECT().
+					CreateRefundClaim(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/refunds", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			request.SetBasicAuth("adminTest", "test!")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminListRefundClaimsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?status=under_review",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListRefundClaims(gomock.Any(), gomock.Eq(db.ListRefundClaimsParams{
+						Status: sql.NullString{String: "under_review", Valid: true},
+					})).
+					Times(1).
+					Return([]db.RefundClaim{*newRefundClaim("under_review")}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []RefundClaimResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Len(t, res, 1)
+				require.Equal(t, "under_review", res[0].Status)
+			},
+		},
+		{
+			name: "OK Empty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListRefundClaims(gomock.Any(), gomock.Eq(db.ListRefundClaimsParams{})).
+					Times(1).
+					Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "[]\n", recorder.Body.String())
+			},
+		},
+		{
+			name:       "Invalid Status",
+			query:      "?status=rejected",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to List Refund Claims",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListRefundClaims(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/refunds"+tc.query, nil)
+			require.NoError(t, err)
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminGetRefundClaimAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		id            string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK Accruing Interest",
+			id:   "1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("under_review"), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res RefundClaimResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, "2025-06-30", res.DueDate.String())
+				require.Positive(t, res.MonthsLate)
+				require.True(t, res.Interest.IsPositive())
+				require.True(t, res.PaidAt.IsZero())
+			},
+		},
+		{
+			name:       "Invalid ID",
+			id:         "abc",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Refund Claim",
+			id:   "2",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(2)).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/refunds/"+tc.id, nil)
+			require.NoError(t, err)
+
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminUpdateRefundStatusAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK Under Review",
+			body: map[string]interface{}{"status": "under_review"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("submitted"), nil)
+				store.EXPECT().
+					UpdateRefundClaimStatus(gomock.Any(), gomock.Eq(1), eqUpdateRefundClaimStatusParams(db.UpdateRefundClaimStatusParams{
+						FromStatus: "submitted",
+						Status:     "under_review",
+						Interest:   decimal.Zero,
+						UpdatedBy:  "adminTest",
+					})).
+					Times(1).
+					Return(newRefundClaim("under_review"), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OK Paid Late",
+			body: map[string]interface{}{"status": "paid", "paidAt": "2025-08-15"},
+			buildStubs: func(store *mockdb.MockStore) {
+				paidAt := time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC)
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("under_review"), nil)
+				store.EXPECT().
+					UpdateRefundClaimStatus(gomock.Any(), gomock.Eq(1), eqUpdateRefundClaimStatusParams(db.UpdateRefundClaimStatusParams{
+						FromStatus: "under_review",
+						Status:     "paid",
+						PaidAt:     sql.NullTime{Time: paidAt, Valid: true},
+						Interest:   decimal.NewFromInt(200),
+						UpdatedBy:  "adminTest",
+					})).
+					Times(1).
+					DoAndReturn(func(_ interface{}, _ int, arg db.UpdateRefundClaimStatusParams) (*db.RefundClaim, error) {
+						claim := newRefundClaim(arg.Status)
+						claim.PaidAt = arg.PaidAt
+						claim.Interest = arg.Interest
+						return claim, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res RefundClaimResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, "paid", res.Status)
+				require.Equal(t, 2, res.MonthsLate)
+				require.Equal(t, "200", res.Interest.String())
+				require.Equal(t, "2025-08-15", res.PaidAt.String())
+			},
+		},
+		{
+			name: "Invalid Transition",
+			body: map[string]interface{}{"status": "paid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("submitted"), nil)
+				store.EXPECT().
+					UpdateRefundClaimStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Status)",
+			body: map[string]interface{}{"status": "rejected"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Moved Back To Submitted)",
+			body: map[string]interface{}{"status": "submitted"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Paid In The Future)",
+			body: map[string]interface{}{"status": "paid", "paidAt": tax.Today().AddDate(0, 0, 1).Format(time.DateOnly)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Paid Before Filing)",
+			body: map[string]interface{}{"status": "paid", "paidAt": "2025-01-01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("under_review"), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Refund Claim",
+			body: map[string]interface{}{"status": "under_review"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Status Changed Concurrently",
+			body: map[string]interface{}{"status": "under_review"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("submitted"), nil)
+				store.EXPECT().
+					UpdateRefundClaimStatus(gomock.Any(), gomock.Eq(1), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrRefundClaimStatusChanged)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Update Refund Claim",
+			body: map[string]interface{}{"status": "under_review"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRefundClaim(gomock.Any(), gomock.Eq(1)).
+					Times(1).
+					Return(newRefundClaim("submitted"), nil)
+				store.EXPECT().
+					UpdateRefundClaimStatus(gomock.Any(), gomock.Eq(1), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := config.Config{
+				AdminUsername: "adminTest",
+				AdminPassword: "test!",
+			}
+
+			server := NewServer(&cfg, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/admin/refunds/1/status", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			request.SetBasicAuth("adminTest", "test!")
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
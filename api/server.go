@@ -7,6 +7,7 @@ import (
 	"github.com/danyouknowme/assessment-tax/config"
 	"github.com/danyouknowme/assessment-tax/db"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 )
 
 type Server struct {
@@ -35,10 +36,33 @@ func (s *Server) setupRouter() {
 	}
 	e.Validator = validator
 
+	// The server is reached directly, so the client IP recorded in the audit
+	// log is the peer address and never a header the client controls.
+	e.IPExtractor = echo.ExtractIPDirect()
+
+	e.Use(dropInvalidRequestID)
+	e.Use(middleware.RequestID())
+
 	e.POST("/tax/calculations", s.CalculateTax)
 	e.POST("/tax/calculations/upload-csv", s.acceptCSVExtension(s.CalculateTaxForCSV))
-	e.POST("/admin/deductions/personal", s.basicAuth(s.SettingPersonalDeduction))
-	e.POST("/admin/deductions/k-receipt", s.basicAuth(s.SettingKReceiptDeduction))
+	e.POST("/tax/gross-up", s.GrossUp)
+	e.POST("/tax/withholding", s.ScheduleWithholding)
+	e.POST("/tax/scenarios", s.CompareScenarios)
+	e.POST("/tax/optimize", s.Optimize)
+	e.POST("/tax/payment-plan", s.PlanPayment)
+	e.GET("/admin/deductions", s.basicAuth(s.ListDeductions))
+	e.POST("/admin/deductions/:type", s.basicAuth(s.SettingDeduction))
+	e.PUT("/admin/deductions/:type", s.basicAuth(s.SettingDeduction))
+	e.GET("/admin/deductions/:type/history", s.basicAuth(s.GetDeductionHistory))
+	e.GET("/admin/brackets", s.basicAuth(s.ListTaxBrackets))
+	e.GET("/admin/brackets/:year", s.basicAuth(s.GetTaxBrackets))
+	e.POST("/admin/brackets", s.basicAuth(s.CreateTaxBrackets))
+	e.PUT("/admin/brackets/:year", s.basicAuth(s.ReplaceTaxBrackets))
+	e.DELETE("/admin/brackets/:year", s.basicAuth(s.DeleteTaxBrackets))
+	e.GET("/admin/refunds", s.basicAuth(s.ListRefundClaims))
+	e.POST("/admin/refunds", s.basicAuth(s.ClaimRefund))
+	e.GET("/admin/refunds/:id", s.basicAuth(s.GetRefundClaim))
+	e.PUT("/admin/refunds/:id/status", s.basicAuth(s.UpdateRefundStatus))
 
 	s.router = e
 }
@@ -4,21 +4,104 @@ import (
 	"database/sql"
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/danyouknowme/assessment-tax/db"
 	"github.com/danyouknowme/assessment-tax/tax"
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/shopspring/decimal"
 )
 
+// CalculateTaxResponse always carries the full breakdown, also for refunds.
+// The optional parts are present only when they apply to the request.
 type CalculateTaxResponse struct {
-	Tax       float64        `json:"tax"`
-	TaxRefund float64        `json:"taxRefund"`
-	TaxLevel  []tax.TaxLevel `json:"taxLevel"`
+	Tax                  decimal.Decimal       `json:"tax"`
+	TaxRefund            decimal.Decimal       `json:"taxRefund"`
+	TaxLevel             []tax.TaxLevel        `json:"taxLevel"`
+	TaxableIncome        decimal.Decimal       `json:"taxableIncome"`
+	TotalAllowances      decimal.Decimal       `json:"totalAllowances"`
+	MarginalBracket      string                `json:"marginalBracket"`
+	MarginalRate         decimal.Decimal       `json:"marginalRate"`
+	EffectiveRate        decimal.Decimal       `json:"effectiveRate"`
+	EffectiveTaxableRate decimal.Decimal       `json:"effectiveTaxableRate"`
+	NetIncome            decimal.Decimal       `json:"netIncome"`
+	DonationCap          *decimal.Decimal      `json:"donationCap,omitempty"`
+	Incomes              []tax.IncomeExpense   `json:"incomes,omitempty"`
+	TaxMethods           *tax.TaxMethods       `json:"taxMethods,omitempty"`
+	Credits              *tax.Credits          `json:"credits,omitempty"`
+	Filing               *tax.FilingComparison `json:"filing,omitempty"`
+	Trace                *tax.Trace            `json:"trace,omitempty"` // with explain=true only
+}
+
+func newCalculateTaxResponse(result tax.Result, explain bool) CalculateTaxResponse {
+	res := CalculateTaxResponse{
+		Tax:                  result.Tax,
+		TaxRefund:            result.TaxRefund,
+		TaxLevel:             result.TaxLevels,
+		TaxableIncome:        result.TaxableIncome,
+		TotalAllowances:      result.TotalAllowances,
+		MarginalBracket:      result.MarginalBracket,
+		MarginalRate:         result.MarginalRate,
+		EffectiveRate:        result.EffectiveRate,
+		EffectiveTaxableRate: result.EffectiveTaxableRate,
+		NetIncome:            result.NetIncome,
+		Incomes:              result.Expenses,
+		TaxMethods:           result.TaxMethods,
+		Credits:              result.Credits,
+		Filing:               result.Filing,
+	}
+
+	if result.DonationCap.Valid {
+		res.DonationCap = &result.DonationCap.Decimal
+	}
+
+	if explain {
+		res.Trace = &result.Trace
+	}
+
+	return res
+}
+
+// loadTaxTables loads the deductions in force on asOf and the tax brackets
+// of year. On failure it returns the status to answer the error with.
+func (s *Server) loadTaxTables(c echo.Context, asOf tax.Date, year int) ([]db.TaxBracket, []db.Deduction, int, error) {
+	defaultDeductions, err := s.store.GetAllDeductions(c.Request().Context(), asOf.Time)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, http.StatusNotFound, errors.New("invalid deduction type not found")
+		}
+
+		return nil, nil, http.StatusInternalServerError, errors.New("failed to get deductions")
+	}
+
+	taxBrackets, err := s.store.GetTaxBracketsByYear(c.Request().Context(), year)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, http.StatusNotFound, fmt.Errorf("tax brackets for tax year %d not found", year)
+		}
+
+		return nil, nil, http.StatusInternalServerError, errors.New("failed to get tax brackets")
+	}
+
+	return taxBrackets, defaultDeductions, http.StatusOK, nil
 }
 
 func (s *Server) CalculateTax(c echo.Context) error {
+	explain := false
+	if value := c.QueryParam("explain"); value != "" {
+		var err error
+		explain, err = strconv.ParseBool(value)
+		if err != nil {
+			err := errors.New("invalid explain")
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		}
+	}
+
 	var req tax.CalculationRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
@@ -28,30 +111,211 @@ func (s *Server) CalculateTax(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
 	}
 
-	defaultDeductions, err := s.store.GetAllDeductions(c.Request().Context())
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, req.AsOfDate(), req.Year())
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err := errors.New("invalid deduction type not found")
-			return c.JSON(http.StatusNotFound, errorResponse(err))
+		return c.JSON(status, errorResponse(err))
+	}
+
+	result := tax.Calculate(taxBrackets, defaultDeductions, req)
+
+	return c.JSON(http.StatusOK, newCalculateTaxResponse(result, explain))
+}
+
+type GrossUpResponse struct {
+	GrossIncome decimal.Decimal `json:"grossIncome"`
+	CalculateTaxResponse
+}
+
+// GrossUp finds the gross income that yields the requested net income after
+// tax and returns it with its calculation.
+func (s *Server) GrossUp(c echo.Context) error {
+	var req tax.GrossUpRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	calculationReq := req.CalculationRequest(decimal.Zero)
+
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, calculationReq.AsOfDate(), calculationReq.Year())
+	if err != nil {
+		return c.JSON(status, errorResponse(err))
+	}
+
+	grossIncome, result, err := tax.GrossUp(taxBrackets, defaultDeductions, req)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	}
+
+	if !req.Wht.LessThan(grossIncome) {
+		err := errors.New("wht must be less than the gross income")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, GrossUpResponse{
+		GrossIncome:          grossIncome,
+		CalculateTaxResponse: newCalculateTaxResponse(result, false),
+	})
+}
+
+// ScheduleWithholding spreads the annual tax on a monthly salary and its
+// bonuses over the months of the year.
+func (s *Server) ScheduleWithholding(c echo.Context) error {
+	var req tax.WithholdingRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	calculationReq := req.CalculationRequest(decimal.Zero)
+
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, calculationReq.AsOfDate(), calculationReq.Year())
+	if err != nil {
+		return c.JSON(status, errorResponse(err))
+	}
+
+	return c.JSON(http.StatusOK, tax.ScheduleWithholding(taxBrackets, defaultDeductions, req))
+}
+
+type ScenarioResponse struct {
+	Name string `json:"name"`
+	CalculateTaxResponse
+	Delta tax.ScenarioDelta `json:"delta"`
+}
+
+type CompareScenariosResponse struct {
+	Base      CalculateTaxResponse `json:"base"`
+	Scenarios []ScenarioResponse   `json:"scenarios"`
+}
+
+// CompareScenarios calculates a base request and named variations of it
+// against the same deductions and brackets, with the delta of each
+// variation versus the base.
+func (s *Server) CompareScenarios(c echo.Context) error {
+	var req tax.ScenarioRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	for _, scenario := range req.Scenarios {
+		if err := c.Validate(scenario.Apply(req.Base)); err != nil {
+			err := fmt.Errorf("scenario %q: %w", scenario.Name, err)
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
 		}
+	}
 
-		err := errors.New("failed to get deductions")
-		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, req.Base.AsOfDate(), req.Base.Year())
+	if err != nil {
+		return c.JSON(status, errorResponse(err))
 	}
 
-	taxVal, taxRefund := tax.Calculate(defaultDeductions, req)
-	taxLevels := tax.GetTaxLevels(defaultDeductions, req)
+	base, results := tax.CompareScenarios(taxBrackets, defaultDeductions, req)
 
-	if taxRefund > 0 {
-		return c.JSON(http.StatusOK, CalculateTaxResponse{
-			Tax:       0,
-			TaxRefund: taxRefund,
+	res := CompareScenariosResponse{
+		Base:      newCalculateTaxResponse(base, false),
+		Scenarios: make([]ScenarioResponse, 0, len(results)),
+	}
+	for _, result := range results {
+		res.Scenarios = append(res.Scenarios, ScenarioResponse{
+			Name:                 result.Name,
+			CalculateTaxResponse: newCalculateTaxResponse(result.Result, false),
+			Delta:                result.Delta,
 		})
 	}
 
-	return c.JSON(http.StatusOK, CalculateTaxResponse{
-		Tax:      taxVal,
-		TaxLevel: taxLevels,
+	return c.JSON(http.StatusOK, res)
+}
+
+type OptimizeResponse struct {
+	Allocations   []tax.Allocation     `json:"allocations"`
+	Invested      decimal.Decimal      `json:"invested"`
+	Unused        decimal.Decimal      `json:"unused"`
+	TaxBefore     decimal.Decimal      `json:"taxBefore"`
+	TaxAfter      decimal.Decimal      `json:"taxAfter"`
+	TaxSaved      decimal.Decimal      `json:"taxSaved"`
+	SavingPerBaht decimal.Decimal      `json:"savingPerBaht"`
+	Calculation   CalculateTaxResponse `json:"calculation"`
+}
+
+// Optimize recommends how to spend a budget on allowances to cut the most
+// tax.
+func (s *Server) Optimize(c echo.Context) error {
+	var req tax.OptimizeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, req.Base.AsOfDate(), req.Base.Year())
+	if err != nil {
+		return c.JSON(status, errorResponse(err))
+	}
+
+	result := tax.Optimize(taxBrackets, defaultDeductions, req)
+
+	return c.JSON(http.StatusOK, OptimizeResponse{
+		Allocations:   result.Allocations,
+		Invested:      result.Invested,
+		Unused:        result.Unused,
+		TaxBefore:     result.TaxBefore,
+		TaxAfter:      result.TaxAfter,
+		TaxSaved:      result.TaxSaved,
+		SavingPerBaht: result.SavingPerBaht,
+		Calculation:   newCalculateTaxResponse(result.Result, false),
+	})
+}
+
+type PaymentPlanResponse struct {
+	tax.PaymentPlan
+	Calculation CalculateTaxResponse `json:"calculation"`
+}
+
+// PlanPayment works out the surcharge, fine and dated payments of the tax
+// owed when filing and paying on the given dates.
+func (s *Server) PlanPayment(c echo.Context) error {
+	var req tax.PaymentPlanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	calculation := req.TaxCalculation()
+	if req.FiledOn().Year() <= calculation.Year() {
+		err := errors.New("filing date must be after the tax year")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	if req.PaidOn().Before(req.FiledOn().Time) {
+		err := errors.New("payment date must not be before the filing date")
+		return c.JSON(http.StatusBadRequest, errorResponse(err))
+	}
+
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, calculation.AsOfDate(), calculation.Year())
+	if err != nil {
+		return c.JSON(status, errorResponse(err))
+	}
+
+	result := tax.Calculate(taxBrackets, defaultDeductions, calculation)
+
+	return c.JSON(http.StatusOK, PaymentPlanResponse{
+		PaymentPlan: tax.PlanPayment(result.Tax, calculation.Year(), req),
+		Calculation: newCalculateTaxResponse(result, false),
 	})
 }
 
@@ -59,12 +323,79 @@ type CalculateTaxForCSVResponse struct {
 	Taxes []TaxCSV `json:"taxes"`
 }
 
+// TaxCSV is the tax of a row of a CSV upload. ID echoes its id column, and
+// TaxLevel is only present with the levels=true query parameter.
 type TaxCSV struct {
-	TotalIncome float64 `json:"totalIncome"`
-	Tax         float64 `json:"tax"`
+	ID          string          `json:"id,omitempty"`
+	TotalIncome decimal.Decimal `json:"totalIncome"`
+	Tax         decimal.Decimal `json:"tax"`
+	TaxRefund   decimal.Decimal `json:"taxRefund"`
+	TaxLevel    []tax.TaxLevel  `json:"taxLevel,omitempty"`
+}
+
+// CalculateTaxForCSVRowsResponse is returned with the rowErrors=true query
+// parameter: every row gets either its tax or the errors rejecting it.
+type CalculateTaxForCSVRowsResponse struct {
+	Rows    []TaxCSVRow `json:"rows"`
+	Summary CSVSummary  `json:"summary"`
 }
 
+// TaxCSVRow is the outcome of the row on Line of a CSV upload, counting the
+// header as line 1.
+type TaxCSVRow struct {
+	Line        int              `json:"line"`
+	ID          string           `json:"id,omitempty"`
+	TotalIncome *decimal.Decimal `json:"totalIncome,omitempty"`
+	Tax         *decimal.Decimal `json:"tax,omitempty"`
+	TaxRefund   *decimal.Decimal `json:"taxRefund,omitempty"`
+	TaxLevel    []tax.TaxLevel   `json:"taxLevel,omitempty"`
+	Errors      []CSVRowError    `json:"errors,omitempty"`
+}
+
+type CSVSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// CSVRowError tells why a row of a CSV upload was rejected. Column is empty
+// when the row as a whole is malformed.
+type CSVRowError struct {
+	Line   int    `json:"line"`
+	Column string `json:"column,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e CSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// CalculateTaxForCSV calculates the tax of every row of the uploaded file,
+// validated by the same rules as CalculateTax. The first invalid row fails
+// the whole file unless the rowErrors=true query parameter asks for the
+// errors of each row instead. The levels=true query parameter adds the tax
+// of each bracket to every row.
 func (s *Server) CalculateTaxForCSV(c echo.Context) error {
+	rowErrors := false
+	if value := c.QueryParam("rowErrors"); value != "" {
+		var err error
+		rowErrors, err = strconv.ParseBool(value)
+		if err != nil {
+			err := errors.New("invalid rowErrors")
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		}
+	}
+
+	levels := false
+	if value := c.QueryParam("levels"); value != "" {
+		var err error
+		levels, err = strconv.ParseBool(value)
+		if err != nil {
+			err := errors.New("invalid levels")
+			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		}
+	}
+
 	file, err := c.FormFile("taxFile")
 	if err != nil {
 		err := errors.New("missing file")
@@ -89,38 +420,75 @@ func (s *Server) CalculateTaxForCSV(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, errorResponse(err))
 	}
 
-	defaultDeductions, err := s.store.GetAllDeductions(c.Request().Context())
+	today := tax.Today()
+	taxBrackets, defaultDeductions, status, err := s.loadTaxTables(c, today, today.Year())
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err := errors.New("invalid deduction type not found")
-			return c.JSON(http.StatusNotFound, errorResponse(err))
-		}
-
-		err := errors.New("failed to get deductions")
-		return c.JSON(http.StatusInternalServerError, errorResponse(err))
+		return c.JSON(status, errorResponse(err))
 	}
 
 	var taxes []TaxCSV
+	rows := CalculateTaxForCSVRowsResponse{Rows: []TaxCSVRow{}}
 	for {
 		record, err := reader.Read()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
+		if errors.Is(err, io.EOF) {
+			break
+		}
 
+		var rowErrs []CSVRowError
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount):
+			rowErrs = []CSVRowError{{Line: parseErr.StartLine, Reason: "invalid csv body"}}
+		case err != nil:
 			return c.JSON(http.StatusBadRequest, errorResponse(err))
 		}
 
-		req, err := validateCSVBodyRequest(record)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse(err))
+		line, _ := reader.FieldPos(0)
+		id := csvRecordID(header, record)
+		var req tax.CalculationRequest
+		if len(rowErrs) == 0 {
+			req, rowErrs = validateCSVBodyRequest(line, header, record, defaultDeductions)
 		}
+		if len(rowErrs) == 0 {
+			rowErrs = validateCSVRow(c, line, req)
+		}
+
+		if len(rowErrs) > 0 {
+			if !rowErrors {
+				return c.JSON(http.StatusBadRequest, errorResponse(rowErrs[0]))
+			}
 
-		taxVal, _ := tax.Calculate(defaultDeductions, req)
-		taxes = append(taxes, TaxCSV{
+			rows.Rows = append(rows.Rows, TaxCSVRow{Line: rowErrs[0].Line, ID: id, Errors: rowErrs})
+			rows.Summary.Failed++
+			continue
+		}
+
+		result := tax.Calculate(taxBrackets, defaultDeductions, req)
+		row := TaxCSV{
+			ID:          id,
 			TotalIncome: req.TotalIncome,
-			Tax:         taxVal,
+			Tax:         result.Tax,
+			TaxRefund:   result.TaxRefund,
+		}
+		if levels {
+			row.TaxLevel = result.TaxLevels
+		}
+
+		taxes = append(taxes, row)
+		rows.Rows = append(rows.Rows, TaxCSVRow{
+			Line:        line,
+			ID:          row.ID,
+			TotalIncome: &row.TotalIncome,
+			Tax:         &row.Tax,
+			TaxRefund:   &row.TaxRefund,
+			TaxLevel:    row.TaxLevel,
 		})
+		rows.Summary.Succeeded++
+	}
+
+	if rowErrors {
+		rows.Summary.Total = rows.Summary.Succeeded + rows.Summary.Failed
+		return c.JSON(http.StatusOK, rows)
 	}
 
 	return c.JSON(http.StatusOK, CalculateTaxForCSVResponse{
@@ -128,46 +496,184 @@ func (s *Server) CalculateTaxForCSV(c echo.Context) error {
 	})
 }
 
+const (
+	csvIDColumn          = "id"
+	csvTotalIncomeColumn = "totalIncome"
+	csvWhtColumn         = "wht"
+)
+
+// validateCSVHeader checks the columns of a CSV upload by name, in any
+// order: totalIncome is required, while id, echoed back to join the results
+// to their source, wht and a column per allowance type are optional.
+// Unknown and repeated columns are rejected.
 func validateCSVHeader(header []string) error {
-	if len(header) != 3 {
-		return errors.New("invalid csv header")
+	seen := make(map[string]bool, len(header))
+	for _, column := range header {
+		if !isCSVFieldColumn(column) && column != csvIDColumn && !tax.IsAllowanceType(column) {
+			return fmt.Errorf("invalid csv header: unknown column %q", column)
+		}
+
+		if seen[column] {
+			return fmt.Errorf("invalid csv header: duplicate column %q", column)
+		}
+		seen[column] = true
 	}
 
-	if header[0] != "totalIncome" || header[1] != "wht" || header[2] != "donation" {
-		return errors.New("invalid csv header")
+	if !seen[csvTotalIncomeColumn] {
+		return fmt.Errorf("invalid csv header: missing column %q", csvTotalIncomeColumn)
 	}
 
 	return nil
 }
 
-func validateCSVBodyRequest(record []string) (tax.CalculationRequest, error) {
-	if len(record) != 3 {
-		return tax.CalculationRequest{}, errors.New("invalid csv body")
+// validateCSVBodyRequest builds the request of the CSV record on line whose
+// columns are named by header, claiming each non-zero allowance column as an
+// allowance of its type. Columns of per-person deductions hold the number of
+// dependants claimed. It reports every column that is not a number.
+func validateCSVBodyRequest(line int, header, record []string, deductions []db.Deduction) (tax.CalculationRequest, []CSVRowError) {
+	if len(record) != len(header) {
+		return tax.CalculationRequest{}, []CSVRowError{{Line: line, Reason: "invalid csv body"}}
 	}
 
-	totalIncome, err := strconv.ParseFloat(record[0], 64)
-	if err != nil {
-		return tax.CalculationRequest{}, errors.New("invalid total income")
+	req := tax.CalculationRequest{
+		Wht:        decimal.Zero,
+		Allowances: []tax.Allowance{},
 	}
 
-	wht, err := strconv.ParseFloat(record[1], 64)
-	if err != nil {
-		return tax.CalculationRequest{}, errors.New("invalid wht")
+	var rowErrs []CSVRowError
+	for i, column := range header {
+		if column == csvIDColumn {
+			continue
+		}
+
+		if isPerPersonDeduction(deductions, column) {
+			count, err := strconv.Atoi(record[i])
+			if err != nil {
+				rowErrs = append(rowErrs, CSVRowError{Line: line, Column: column, Reason: "invalid " + column + " count"})
+			} else if count != 0 {
+				req.Allowances = append(req.Allowances, tax.Allowance{AllowanceType: column, Count: count})
+			}
+			continue
+		}
+
+		amount, err := decimal.NewFromString(record[i])
+		if err != nil {
+			rowErrs = append(rowErrs, CSVRowError{Line: line, Column: column, Reason: "invalid " + csvColumnName(column)})
+			continue
+		}
+
+		switch column {
+		case csvTotalIncomeColumn:
+			req.TotalIncome = amount
+		case csvWhtColumn:
+			req.Wht = amount
+		default:
+			if !amount.IsZero() {
+				req.Allowances = append(req.Allowances, tax.Allowance{
+					AllowanceType: column,
+					Amount:        amount,
+				})
+			}
+		}
 	}
 
-	donation, err := strconv.ParseFloat(record[2], 64)
-	if err != nil {
-		return tax.CalculationRequest{}, errors.New("invalid donation")
-	}
-
-	return tax.CalculationRequest{
-		TotalIncome: totalIncome,
-		Wht:         wht,
-		Allowances: []tax.Allowance{
-			{
-				AllowanceType: "donation",
-				Amount:        donation,
-			},
-		},
-	}, nil
+	return req, rowErrs
+}
+
+// validateCSVRow runs req, built from the row on line, through the validator
+// of the server and reports each failed rule against the column it came from.
+func validateCSVRow(c echo.Context, line int, req tax.CalculationRequest) []CSVRowError {
+	err := c.Validate(req)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return []CSVRowError{{Line: line, Reason: err.Error()}}
+	}
+
+	rowErrs := make([]CSVRowError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		column := csvColumnOf(fe, req.Allowances)
+		rowErrs = append(rowErrs, CSVRowError{Line: line, Column: column, Reason: csvValidationReason(column, fe.Tag())})
+	}
+
+	return rowErrs
+}
+
+// isCSVFieldColumn reports whether column fills a field of the request
+// rather than claiming an allowance.
+func isCSVFieldColumn(column string) bool {
+	return column == csvTotalIncomeColumn || column == csvWhtColumn
+}
+
+// isPerPersonDeduction reports whether the deduction of deductionType is
+// granted per dependant.
+func isPerPersonDeduction(deductions []db.Deduction, deductionType string) bool {
+	for _, deduction := range deductions {
+		if deduction.Type == deductionType {
+			return deduction.PerPerson
+		}
+	}
+
+	return false
+}
+
+// csvRecordID returns the id column of record, empty when there is none.
+func csvRecordID(header, record []string) string {
+	for i, column := range header {
+		if column == csvIDColumn && i < len(record) {
+			return record[i]
+		}
+	}
+
+	return ""
+}
+
+// csvColumnOf returns the CSV column of the request field fe failed on,
+// allowances being those claimed by the row, each named after its column.
+func csvColumnOf(fe validator.FieldError, allowances []tax.Allowance) string {
+	namespace := fe.StructNamespace()
+	if _, field, ok := strings.Cut(namespace, "."); ok {
+		namespace = field
+	}
+
+	switch {
+	case namespace == "TotalIncome":
+		return csvTotalIncomeColumn
+	case namespace == "Wht":
+		return csvWhtColumn
+	case strings.HasPrefix(namespace, "Allowances["):
+		index, _, _ := strings.Cut(strings.TrimPrefix(namespace, "Allowances["), "]")
+		if i, err := strconv.Atoi(index); err == nil && i < len(allowances) {
+			return allowances[i].AllowanceType
+		}
+	}
+
+	return namespace
+}
+
+func csvValidationReason(column, tag string) string {
+	name := csvColumnName(column)
+	switch tag {
+	case "wht_custom_validation":
+		return "wht must not be negative and must be less than the total income"
+	case "required", "required_without":
+		return name + " is required"
+	case "min":
+		return name + " must not be negative"
+	}
+
+	return fmt.Sprintf("%s failed on the %s rule", name, tag)
+}
+
+// csvColumnName names column in messages, keeping the wording of the
+// original totalIncome,wht,donation upload.
+func csvColumnName(column string) string {
+	if column == csvTotalIncomeColumn {
+		return "total income"
+	}
+
+	return column
 }
@@ -40,7 +40,7 @@ func TestIntegrationCalculateTax(t *testing.T) {
 	assert.NoError(t, err)
 	resp.Body.Close()
 
-	expected := `{"tax":14000,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":14000},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}]}`
+	expected := `{"tax":20100,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":20100},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":351000,"totalAllowances":149000,"marginalBracket":"150,001-500,000","marginalRate":10,"effectiveRate":4.02,"effectiveTaxableRate":5.73,"netIncome":479900,"donationCap":39000}`
 
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 	require.Equal(t, expected, strings.TrimSpace(string(byteBody)))
@@ -76,7 +76,7 @@ func TestIntegrationCalculateTaxForCSV(t *testing.T) {
 	byteBody, err := ioutil.ReadAll(resp.Body)
 	assert.NoError(t, err)
 
-	expected := `{"taxes":[{"totalIncome":500000,"tax":29000},{"totalIncome":600000,"tax":0},{"totalIncome":750000,"tax":11250}]}`
+	expected := `{"taxes":[{"totalIncome":500000,"tax":29000,"taxRefund":0},{"totalIncome":600000,"tax":0,"taxRefund":2000},{"totalIncome":750000,"tax":11250,"taxRefund":0}]}`
 
 	fmt.Println("Response:", string(byteBody))
 
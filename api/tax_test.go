@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -19,13 +20,24 @@ import (
 
 	"github.com/danyouknowme/assessment-tax/db"
 	mockdb "github.com/danyouknowme/assessment-tax/db/mock"
+	"github.com/danyouknowme/assessment-tax/tax"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
+var defaultTaxBrackets = []db.TaxBracket{
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(0), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(150000)), TaxRate: decimal.NewFromInt(0)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(150000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(500000)), TaxRate: decimal.NewFromInt(10)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(500000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(1000000)), TaxRate: decimal.NewFromInt(15)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(1000000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(2000000)), TaxRate: decimal.NewFromInt(20)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(2000000), TaxRate: decimal.NewFromInt(35)},
+}
+
 func TestCalculateTaxAPI(t *testing.T) {
 	testCases := []struct {
 		name          string
+		query         string
 		body          map[string]interface{}
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
@@ -44,16 +56,68 @@ func TestCalculateTaxAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"tax":19000,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":19000},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":340000,"totalAllowances":160000,"marginalBracket":"150,001-500,000","marginalRate":10,"effectiveRate":3.8,"effectiveTaxableRate":5.59,"netIncome":481000,"donationCap":100000}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:  "OK with Trace",
+			query: "?explain=true",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"allowances": []map[string]interface{}{
+					{"allowanceType": "donation", "amount": 200000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.Deduction{
-						{Type: "personal", Amount: 60000.0},
-						{Type: "donation", Amount: 100000.0},
-						{Type: "k-receipt", Amount: 50000.0},
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
 					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res struct {
+					Trace json.RawMessage `json:"trace"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+
+				expected := `{"grossIncome":500000,"expenses":[],"netIncome":500000,"personalAllowance":60000,"allowances":[{"allowanceType":"donation","claimed":200000,"cap":100000,"allowed":100000}],"taxableIncome":340000,"brackets":[{"level":"0-150,000","taxRate":0,"income":150000,"tax":0},{"level":"150,001-500,000","taxRate":10,"income":190000,"tax":19000},{"level":"500,001-1,000,000","taxRate":15,"income":0,"tax":0},{"level":"1,000,001-2,000,000","taxRate":20,"income":0,"tax":0},{"level":"2,000,001 ขึ้นไป","taxRate":35,"income":0,"tax":0}],"progressiveTax":19000,"tax":19000,"whtCredit":0,"taxPayable":19000,"taxRefund":0}`
+				require.Equal(t, expected, string(res.Trace))
+			},
+		},
+		{
+			name:  "Invalid Explain",
+			query: "?explain=maybe",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
@@ -70,18 +134,192 @@ func TestCalculateTaxAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"tax":0,"taxRefund":81000,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":19000},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":340000,"totalAllowances":160000,"marginalBracket":"150,001-500,000","marginalRate":10,"effectiveRate":3.8,"effectiveTaxableRate":5.59,"netIncome":481000,"donationCap":100000}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "OK with Donation Income Percent Cap",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"allowances": []map[string]interface{}{
+					{"allowanceType": "k-receipt", "amount": 200000.0},
+					{"allowanceType": "donation", "amount": 100000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"tax":20100,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":20100},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":351000,"totalAllowances":149000,"marginalBracket":"150,001-500,000","marginalRate":10,"effectiveRate":4.02,"effectiveTaxableRate":5.73,"netIncome":479900,"donationCap":39000}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "OK with Incomes",
+			body: map[string]interface{}{
+				"incomes": []map[string]interface{}{
+					{"category": "40(1)", "amount": 600000.0},
+					{"category": "40(8)", "amount": 300000.0, "expenseMethod": "actual", "actualExpenses": 100000.0},
+				},
+				"wht": 50000.0,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"tax":6000,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":35000},{"level":"500,001-1,000,000","tax":21000},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":640000,"totalAllowances":60000,"marginalBracket":"500,001-1,000,000","marginalRate":15,"effectiveRate":6.22,"effectiveTaxableRate":8.75,"netIncome":844000,"incomes":[{"category":"40(1)","amount":600000,"expenseMethod":"standard","expense":100000},{"category":"40(8)","amount":300000,"expenseMethod":"actual","expense":100000}],"taxMethods":{"progressiveTax":56000,"minimumTax":1500,"method":"progressive"}}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "OK with Minimum Tax",
+			body: map[string]interface{}{
+				"incomes": []map[string]interface{}{
+					{"category": "40(8)", "amount": 2000000.0, "expenseMethod": "actual", "actualExpenses": 1900000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"tax":10000,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":0},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":40000,"totalAllowances":60000,"marginalBracket":"0-150,000","marginalRate":0,"effectiveRate":0.5,"effectiveTaxableRate":25,"netIncome":1990000,"incomes":[{"category":"40(8)","amount":2000000,"expenseMethod":"actual","expense":1900000}],"taxMethods":{"progressiveTax":0,"minimumTax":10000,"method":"minimum"}}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "Invalid Body(TotalIncome with Incomes)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"incomes": []map[string]interface{}{
+					{"category": "40(1)", "amount": 500000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Income Category)",
+			body: map[string]interface{}{
+				"incomes": []map[string]interface{}{
+					{"category": "40(9)", "amount": 500000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Actual Expenses for Salary)",
+			body: map[string]interface{}{
+				"incomes": []map[string]interface{}{
+					{"category": "40(1)", "amount": 500000.0, "expenseMethod": "actual", "actualExpenses": 200000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(WHT Greater Than Incomes)",
+			body: map[string]interface{}{
+				"incomes": []map[string]interface{}{
+					{"category": "40(1)", "amount": 100000.0},
+				},
+				"wht": 200000.0,
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OK As Of",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"asOf":        "2019-06-01",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Eq(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))).
 					Times(1).
 					Return([]db.Deduction{
-						{Type: "personal", Amount: 60000.0},
-						{Type: "donation", Amount: 100000.0},
-						{Type: "k-receipt", Amount: 50000.0},
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
 					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2019)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "Invalid Body(Malformed AsOf)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"asOf":        "June 2019",
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Invalid Body(Missing TotalIncome)",
 			body: map[string]interface{}{
@@ -133,6 +371,51 @@ func TestCalculateTaxAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "OK with Dependant Allowances",
+			body: map[string]interface{}{
+				"totalIncome": 1000000.0,
+				"allowances": []map[string]interface{}{
+					{"allowanceType": "spouse"},
+					{"allowanceType": "child", "count": 2},
+					{"allowanceType": "life-insurance", "amount": 150000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "spouse", Amount: decimal.NewFromInt(60000), PerPerson: true, MaxCount: 1},
+						{Type: "child", Amount: decimal.NewFromInt(30000), PerPerson: true},
+						{Type: "life-insurance", Amount: decimal.NewFromInt(100000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"tax":68000,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":35000},{"level":"500,001-1,000,000","tax":33000},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":720000,"totalAllowances":280000,"marginalBracket":"500,001-1,000,000","marginalRate":15,"effectiveRate":6.8,"effectiveTaxableRate":9.44,"netIncome":932000}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "Invalid Body(Negative Allowance Count)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"allowances": []map[string]interface{}{
+					{"allowanceType": "child", "count": -1},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Invalid Body(Invalid Allowance Type)",
 			body: map[string]interface{}{
@@ -181,7 +464,7 @@ func TestCalculateTaxAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil, sql.ErrNoRows)
 			},
@@ -203,7 +486,7 @@ func TestCalculateTaxAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil, sql.ErrConnDone)
 			},
@@ -211,105 +494,1056 @@ func TestCalculateTaxAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			store := mockdb.NewMockStore(ctrl)
-			tc.buildStubs(store)
-
-			server := NewServer(&config.Config{}, store)
-			recorder := httptest.NewRecorder()
-
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
-			url := "/tax/calculations"
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
-			require.NoError(t, err)
-
-			request.Header.Set("Content-Type", "application/json")
-
-			server.router.ServeHTTP(recorder, request)
-			tc.checkResponse(t, recorder)
-		})
-	}
-}
-
-func TestCalculateTaxForCSV(t *testing.T) {
-	testCases := []struct {
-		name          string
-		filePath      string
-		buildStubs    func(store *mockdb.MockStore)
-		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
-	}{
 		{
-			name:     "OK",
-			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			name: "OK with Spouse",
+			body: map[string]interface{}{
+				"totalIncome": 1000000.0,
+				"wht":         0.0,
+				"allowances":  []map[string]interface{}{},
+				"spouse": map[string]interface{}{
+					"totalIncome": 100000.0,
+					"wht":         0.0,
+				},
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.Deduction{
-						{Type: "personal", Amount: 60000.0},
-						{Type: "donation", Amount: 100000.0},
-						{Type: "k-receipt", Amount: 50000.0},
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "spouse", Amount: decimal.NewFromInt(60000), PerPerson: true, MaxCount: 1},
 					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 
-				expected := `{"taxes":[{"totalIncome":500000,"tax":29000},{"totalIncome":600000,"tax":0},{"totalIncome":750000,"tax":11250}]}`
-				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+				var res CalculateTaxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.NotNil(t, res.Filing)
+				require.Len(t, res.Filing.Options, 2)
+				require.Equal(t, "101000", res.Filing.Options[0].HouseholdTax.String())
+				require.Equal(t, "107000", res.Filing.Options[1].HouseholdTax.String())
+				require.Equal(t, tax.FilingSeparate, res.Filing.Recommended)
 			},
 		},
 		{
-			name:       "Invalid CSV Header",
-			filePath:   filepath.Join("..", "testdata", "taxes_invalid_header.csv"),
+			name: "Invalid Body(Spouse Negative WHT)",
+			body: map[string]interface{}{
+				"totalIncome": 1000000.0,
+				"wht":         0.0,
+				"allowances":  []map[string]interface{}{},
+				"spouse": map[string]interface{}{
+					"totalIncome": 100000.0,
+					"wht":         -1.0,
+				},
+			},
 			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name:     "Invalid CSV Body",
-			filePath: filepath.Join("..", "testdata", "taxes_invalid_body.csv"),
+			name: "OK with Spouse Without Income",
+			body: map[string]interface{}{
+				"totalIncome": 1000000.0,
+				"wht":         0.0,
+				"allowances": []map[string]interface{}{
+					{"allowanceType": "spouse"},
+				},
+				"spouse": map[string]interface{}{},
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.Deduction{
-						{Type: "personal", Amount: 60000.0},
-						{Type: "donation", Amount: 100000.0},
-						{Type: "k-receipt", Amount: 50000.0},
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "spouse", Amount: decimal.NewFromInt(60000), PerPerson: true, MaxCount: 1},
 					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res CalculateTaxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, "92000", res.Tax.String())
+				require.NotNil(t, res.Filing)
+				require.Equal(t, tax.FilingSeparate, res.Filing.Recommended)
 			},
 		},
 		{
-			name:     "Failed to Get Default Deductions",
-			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			name: "OK with Spouse Zero Total Income",
+			body: map[string]interface{}{
+				"totalIncome": 1000000.0,
+				"wht":         0.0,
+				"allowances": []map[string]interface{}{
+					{"allowanceType": "spouse"},
+				},
+				"spouse": map[string]interface{}{
+					"totalIncome": 0.0,
+				},
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(nil, sql.ErrConnDone)
-			},
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "spouse", Amount: decimal.NewFromInt(60000), PerPerson: true, MaxCount: 1},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res CalculateTaxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, "92000", res.Tax.String())
+				require.NotNil(t, res.Filing)
+				require.Equal(t, tax.FilingSeparate, res.Filing.Recommended)
+			},
+		},
+		{
+			name: "Invalid Body(Spouse WHT Over Income)",
+			body: map[string]interface{}{
+				"totalIncome": 1000000.0,
+				"wht":         0.0,
+				"allowances":  []map[string]interface{}{},
+				"spouse": map[string]interface{}{
+					"totalIncome": 100000.0,
+					"wht":         100000.0,
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OK with Dividends and Foreign Incomes",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"allowances":  []map[string]interface{}{},
+				"dividends": []map[string]interface{}{
+					{"amount": 100000.0, "wht": 10000.0, "corporateRate": 20.0},
+				},
+				"foreignIncomes": []map[string]interface{}{
+					{"category": "40(8)", "amount": 200000.0, "country": "SG", "foreignTax": 1000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res CalculateTaxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.NotNil(t, res.Credits)
+				require.Equal(t, "10000", res.Credits.DividendWht.String())
+				require.Equal(t, "25000", res.Credits.DividendCredit.String())
+				require.Equal(t, "1000", res.Credits.ForeignTaxCredit.String())
+				require.Equal(t, "645000", res.TaxableIncome.String())
+				require.Equal(t, "20750", res.Tax.String())
+			},
+		},
+		{
+			name: "Invalid Body(Dividend WHT Exceeds Amount)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"dividends": []map[string]interface{}{
+					{"amount": 100000.0, "wht": 200000.0, "corporateRate": 20.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Dividend Option)",
+			body: map[string]interface{}{
+				"totalIncome":    500000.0,
+				"wht":            0.0,
+				"dividendOption": "invalid",
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Negative Foreign Tax)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"foreignIncomes": []map[string]interface{}{
+					{"category": "40(8)", "amount": 200000.0, "foreignTax": -1.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Foreign Income Category)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"foreignIncomes": []map[string]interface{}{
+					{"category": "40(9)", "amount": 200000.0, "foreignTax": 1000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OK with Tax Year",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"taxYear":     2020,
+				"allowances":  []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2020)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Tax Year)",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"taxYear":     2567,
+				"allowances":  []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Tax Brackets",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"taxYear":     2000,
+				"allowances":  []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2000)).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Get Tax Brackets",
+			body: map[string]interface{}{
+				"totalIncome": 500000.0,
+				"wht":         0.0,
+				"allowances":  []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(&config.Config{}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/tax/calculations" + tc.query
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCalculateTaxForCSV(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filePath      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"taxes":[{"totalIncome":500000,"tax":29000,"taxRefund":0},{"totalIncome":600000,"tax":0,"taxRefund":2000},{"totalIncome":750000,"tax":11250,"taxRefund":0}]}`
+				require.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:       "Invalid CSV Header",
+			filePath:   filepath.Join("..", "testdata", "taxes_invalid_header.csv"),
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OK Allowance Columns In Any Order",
+			filePath: filepath.Join("..", "testdata", "taxes_allowances.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"taxes":[{"totalIncome":500000,"tax":24000,"taxRefund":0},{"totalIncome":600000,"tax":38000,"taxRefund":0}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:     "OK Per-Person Allowance Columns Count Dependants",
+			filePath: filepath.Join("..", "testdata", "taxes_children.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "child", Amount: decimal.NewFromInt(30000), PerPerson: true},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"taxes":[{"totalIncome":500000,"tax":29000,"taxRefund":0},{"totalIncome":500000,"tax":23000,"taxRefund":0}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:     "OK With ID And Levels",
+			filePath: filepath.Join("..", "testdata", "taxes_employees.csv"),
+			query:    "?levels=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"taxes":[{"id":"E001","totalIncome":500000,"tax":0,"taxRefund":6000,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":29000},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}]}]}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:       "Invalid Levels",
+			filePath:   filepath.Join("..", "testdata", "taxes.csv"),
+			query:      "?levels=maybe",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "Unknown CSV Column",
+			filePath:   filepath.Join("..", "testdata", "taxes_unknown_column.csv"),
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				expected := `{"error":"invalid csv header: unknown column \"bonus\""}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:     "Invalid CSV Body",
+			filePath: filepath.Join("..", "testdata", "taxes_invalid_body.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Invalid CSV Body(WHT Exceeds Total Income)",
+			filePath: filepath.Join("..", "testdata", "taxes_invalid_wht.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				expected := `{"error":"line 2: wht must not be negative and must be less than the total income"}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:     "OK Row Errors",
+			filePath: filepath.Join("..", "testdata", "taxes_invalid_body.csv"),
+			query:    "?rowErrors=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"rows":[{"line":2,"errors":[{"line":2,"column":"donation","reason":"invalid donation"}]},{"line":3,"totalIncome":600000,"tax":0,"taxRefund":2000},{"line":4,"totalIncome":750000,"tax":11250,"taxRefund":0}],"summary":{"total":3,"succeeded":2,"failed":1}}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name:     "OK Row Errors Failing Validation",
+			filePath: filepath.Join("..", "testdata", "taxes_invalid_wht.csv"),
+			query:    "?rowErrors=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res CalculateTaxForCSVRowsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, CSVSummary{Total: 2, Succeeded: 0, Failed: 2}, res.Summary)
+				require.Equal(t, []CSVRowError{{Line: 2, Column: "wht", Reason: "wht must not be negative and must be less than the total income"}}, res.Rows[0].Errors)
+				require.Equal(t, 3, res.Rows[1].Line)
+				require.Contains(t, res.Rows[1].Errors, CSVRowError{Line: 3, Column: "totalIncome", Reason: "total income must not be negative"})
+			},
+		},
+		{
+			name:       "Invalid Row Errors",
+			filePath:   filepath.Join("..", "testdata", "taxes.csv"),
+			query:      "?rowErrors=maybe",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Failed to Get Default Deductions",
+			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "Not Found Tax Brackets",
+			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Not Found Default Deductions",
+			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(&config.Config{}, store)
+			recorder := httptest.NewRecorder()
+
+			var file *os.File
+			file, err := os.Open(tc.filePath)
+			assert.NoError(t, err)
+			defer file.Close()
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, err := createFormFile(writer, file.Name())
+			require.NoError(t, err)
+
+			_, err = io.Copy(part, file)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/tax/calculations/upload-csv"+tc.query, body)
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGrossUpAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"netIncome":  471000.0,
+				"wht":        25000.0,
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected := `{"grossIncome":500000,"tax":4000,"taxRefund":0,"taxLevel":[{"level":"0-150,000","tax":0},{"level":"150,001-500,000","tax":29000},{"level":"500,001-1,000,000","tax":0},{"level":"1,000,001-2,000,000","tax":0},{"level":"2,000,001 ขึ้นไป","tax":0}],"taxableIncome":440000,"totalAllowances":60000,"marginalBracket":"150,001-500,000","marginalRate":10,"effectiveRate":5.8,"effectiveTaxableRate":6.59,"netIncome":471000}`
+				require.Equal(t, expected, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+		{
+			name: "Invalid Body(Missing Net Income)",
+			body: map[string]interface{}{
+				"wht":        0.0,
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(WHT Exceeds Gross Income)",
+			body: map[string]interface{}{
+				"netIncome":  100000.0,
+				"wht":        200000.0,
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Unsolvable",
+			body: map[string]interface{}{
+				"netIncome":  500000.0,
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.TaxBracket{
+						{MinTotalIncome: decimal.Zero, MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(150000)), TaxRate: decimal.Zero},
+						{MinTotalIncome: decimal.NewFromInt(150000), TaxRate: decimal.NewFromInt(100)},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Tax Brackets",
+			body: map[string]interface{}{
+				"netIncome":  500000.0,
+				"taxYear":    2000,
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2000)).
+					Times(1).
+					Return(nil, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, `{"error":"tax brackets for tax year 2000 not found"}`, strings.TrimSpace(recorder.Body.String()))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(&config.Config{}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tax/gross-up", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestScheduleWithholdingAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"monthlySalary": 50000.0,
+				"bonuses": []map[string]interface{}{
+					{"month": 6, "amount": 100000.0},
+				},
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var schedule tax.WithholdingSchedule
+				err := json.Unmarshal(recorder.Body.Bytes(), &schedule)
+				require.NoError(t, err)
+
+				require.Equal(t, "700000", schedule.AnnualIncome.String())
+				require.Equal(t, "41000", schedule.AnnualTax.String())
+				require.Len(t, schedule.Months, 12)
+				require.Equal(t, "14416.67", schedule.Months[5].Withholding.String())
+				require.Equal(t, "41000", schedule.Months[11].CumulativeWithholding.String())
+			},
+		},
+		{
+			name: "Invalid Body(Missing Monthly Salary)",
+			body: map[string]interface{}{
+				"allowances": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Bonus Month)",
+			body: map[string]interface{}{
+				"monthlySalary": 50000.0,
+				"bonuses": []map[string]interface{}{
+					{"month": 13, "amount": 100000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Get Tax Brackets",
+			body: map[string]interface{}{
+				"monthlySalary": 50000.0,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(&config.Config{}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tax/withholding", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCompareScenariosAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"wht":         0.0,
+					"allowances":  []map[string]interface{}{},
+				},
+				"scenarios": []map[string]interface{}{
+					{
+						"name": "donate",
+						"allowances": []map[string]interface{}{
+							{"allowanceType": "donation", "amount": 100000.0},
+						},
+					},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res CompareScenariosResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, "29000", res.Base.Tax.String())
+				require.Len(t, res.Scenarios, 1)
+				require.Equal(t, "donate", res.Scenarios[0].Name)
+				require.Equal(t, "19000", res.Scenarios[0].Tax.String())
+				require.Equal(t, "-10000", res.Scenarios[0].Delta.Tax.String())
+				require.Equal(t, "100000", res.Scenarios[0].Delta.TotalAllowances.String())
+			},
+		},
+		{
+			name: "Invalid Body(No Scenarios)",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 500000.0,
+				},
+				"scenarios": []map[string]interface{}{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name:     "Not Found Default Deductions",
-			filePath: filepath.Join("..", "testdata", "taxes.csv"),
+			name: "Invalid Body(Duplicate Scenario Names)",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 500000.0,
+				},
+				"scenarios": []map[string]interface{}{
+					{"name": "raise", "totalIncome": 600000.0},
+					{"name": "raise", "totalIncome": 700000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Scenario WHT Exceeds Income)",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 500000.0,
+				},
+				"scenarios": []map[string]interface{}{
+					{"name": "withheld", "wht": 600000.0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Scenario Invalid Allowance Type)",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 500000.0,
+				},
+				"scenarios": []map[string]interface{}{
+					{
+						"name": "invalid",
+						"allowances": []map[string]interface{}{
+							{"allowanceType": "invalid", "amount": 100000.0},
+						},
+					},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found Tax Brackets",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"taxYear":     2000,
+				},
+				"scenarios": []map[string]interface{}{
+					{"name": "raise", "totalIncome": 600000.0},
+				},
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAllDeductions(gomock.Any()).
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2000)).
 					Times(1).
 					Return(nil, sql.ErrNoRows)
 			},
@@ -330,24 +1564,315 @@ func TestCalculateTaxForCSV(t *testing.T) {
 			server := NewServer(&config.Config{}, store)
 			recorder := httptest.NewRecorder()
 
-			var file *os.File
-			file, err := os.Open(tc.filePath)
-			assert.NoError(t, err)
-			defer file.Close()
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
 
-			body := new(bytes.Buffer)
-			writer := multipart.NewWriter(body)
-			part, err := createFormFile(writer, file.Name())
+			request, err := http.NewRequest(http.MethodPost, "/tax/scenarios", bytes.NewReader(data))
 			require.NoError(t, err)
 
-			_, err = io.Copy(part, file)
+			request.Header.Set("Content-Type", "application/json")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOptimizeAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 1000000.0,
+					"allowances":  []map[string]interface{}{},
+				},
+				"budget": 100000.0,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+						{Type: "donation", Amount: decimal.NewFromInt(100000)},
+						{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res OptimizeResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Len(t, res.Allocations, 2)
+				require.Equal(t, "k-receipt", res.Allocations[0].AllowanceType)
+				require.Equal(t, "50000", res.Allocations[0].Amount.String())
+				require.Equal(t, "donation", res.Allocations[1].AllowanceType)
+				require.Equal(t, "50000", res.Allocations[1].Amount.String())
+				require.Equal(t, "15000", res.TaxSaved.String())
+				require.Equal(t, "0.15", res.SavingPerBaht.String())
+				require.Equal(t, "86000", res.Calculation.Tax.String())
+			},
+		},
+		{
+			name: "Invalid Body(Missing Budget)",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 1000000.0,
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Allowance Type)",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 1000000.0,
+				},
+				"budget":         100000.0,
+				"allowanceTypes": []string{"invalid"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Failed to Get Default Deductions",
+			body: map[string]interface{}{
+				"base": map[string]interface{}{
+					"totalIncome": 1000000.0,
+				},
+				"budget": 100000.0,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(&config.Config{}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
 			require.NoError(t, err)
-			require.NoError(t, writer.Close())
 
-			request, err := http.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+			request, err := http.NewRequest(http.MethodPost, "/tax/optimize", bytes.NewReader(data))
 			require.NoError(t, err)
 
-			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Content-Type", "application/json")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPlanPaymentAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"wht":         0.0,
+					"taxYear":     2024,
+					"allowances":  []map[string]interface{}{},
+				},
+				"filingDate":   "2025-03-01",
+				"installments": true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2024)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res PaymentPlanResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, "2025-03-31", res.Deadline.String())
+				require.True(t, res.Installments)
+				require.Len(t, res.Payments, 3)
+				require.Equal(t, "9666.67", res.Payments[0].Amount.String())
+				require.Equal(t, "2025-05-31", res.Payments[2].DueDate.String())
+				require.Equal(t, "9666.66", res.Payments[2].Amount.String())
+				require.Equal(t, "29000", res.Calculation.Tax.String())
+			},
+		},
+		{
+			name: "OK Filed Late",
+			body: map[string]interface{}{
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"wht":         0.0,
+					"taxYear":     2024,
+					"allowances":  []map[string]interface{}{},
+				},
+				"filingDate":  "2025-04-15",
+				"paymentDate": "2025-05-15",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(2024)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res PaymentPlanResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, 2, res.MonthsLate)
+				require.Equal(t, "870", res.Surcharge.String())
+				require.Equal(t, "200", res.Penalty.String())
+				require.Equal(t, "30070", res.Total.String())
+				require.Len(t, res.Payments, 1)
+			},
+		},
+		{
+			name: "OK Default Tax Year",
+			body: map[string]interface{}{
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAllDeductions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Deduction{
+						{Type: "personal", Amount: decimal.NewFromInt(60000)},
+					}, nil)
+				store.EXPECT().
+					GetTaxBracketsByYear(gomock.Any(), gomock.Eq(tax.Today().Year()-1)).
+					Times(1).
+					Return(defaultTaxBrackets, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res PaymentPlanResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				require.Equal(t, tax.FilingDeadline(tax.Today().Year()-1), res.Deadline)
+			},
+		},
+		{
+			name: "Invalid Body(Filing Date Within Tax Year)",
+			body: map[string]interface{}{
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"taxYear":     2024,
+				},
+				"filingDate": "2024-12-01",
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Payment Before Filing)",
+			body: map[string]interface{}{
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"taxYear":     2024,
+				},
+				"filingDate":  "2025-03-01",
+				"paymentDate": "2025-02-01",
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Body(Invalid Filing Date)",
+			body: map[string]interface{}{
+				"calculation": map[string]interface{}{
+					"totalIncome": 500000.0,
+					"taxYear":     2024,
+				},
+				"filingDate": "01/03/2025",
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(&config.Config{}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tax/payment-plan", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
 
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
@@ -1,6 +1,12 @@
 package api
 
-import "github.com/go-playground/validator/v10"
+import (
+	"reflect"
+
+	"github.com/danyouknowme/assessment-tax/tax"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
 
 type CustomValidator struct {
 	validator *validator.Validate
@@ -9,6 +15,9 @@ type CustomValidator struct {
 func NewCustomValidator() (*CustomValidator, error) {
 	validate := validator.New()
 
+	// Let min/max/required tags work on money fields
+	validate.RegisterCustomTypeFunc(decimalValue, decimal.Decimal{})
+
 	// Register custom validation
 	if err := registerAllowanceTypeValidation(validate); err != nil {
 		return nil, err
@@ -16,6 +25,12 @@ func NewCustomValidator() (*CustomValidator, error) {
 	if err := registerWhtValidation(validate); err != nil {
 		return nil, err
 	}
+	if err := registerIncomeValidation(validate); err != nil {
+		return nil, err
+	}
+	if err := registerCreditValidation(validate); err != nil {
+		return nil, err
+	}
 
 	return &CustomValidator{validator: validate}, nil
 }
@@ -24,17 +39,68 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
 }
 
+func decimalValue(field reflect.Value) interface{} {
+	if d, ok := field.Interface().(decimal.Decimal); ok {
+		return d.InexactFloat64()
+	}
+
+	return nil
+}
+
 func registerAllowanceTypeValidation(v *validator.Validate) error {
 	return v.RegisterValidation("allowance_type_custom_validation", func(fl validator.FieldLevel) bool {
-		allowanceType := fl.Field().String()
-		return allowanceType == "donation" || allowanceType == "k-receipt"
+		return tax.IsAllowanceType(fl.Field().String())
 	})
 }
 
 func registerWhtValidation(v *validator.Validate) error {
 	return v.RegisterValidation("wht_custom_validation", func(fl validator.FieldLevel) bool {
-		wht := fl.Field().Float()
-		totalIncome := fl.Parent().FieldByName("TotalIncome").Float()
-		return wht >= 0 && wht < totalIncome
+		wht, ok := fl.Parent().FieldByName(fl.StructFieldName()).Interface().(decimal.Decimal)
+		if !ok {
+			return false
+		}
+
+		switch parent := fl.Parent().Interface().(type) {
+		case tax.CalculationRequest:
+			return !wht.IsNegative() && wht.LessThan(parent.GrossIncome())
+		case tax.Spouse:
+			return wht.IsZero() || wht.IsPositive() && wht.LessThan(parent.GrossIncome())
+		}
+
+		return false
+	})
+}
+
+func registerIncomeValidation(v *validator.Validate) error {
+	err := v.RegisterValidation("income_category_custom_validation", func(fl validator.FieldLevel) bool {
+		return tax.IsIncomeCategory(fl.Field().String())
+	})
+	if err != nil {
+		return err
+	}
+
+	return v.RegisterValidation("expense_method_custom_validation", func(fl validator.FieldLevel) bool {
+		if tax.ExpenseMethod(fl.Field().String()) != tax.ExpenseActual {
+			return true
+		}
+
+		category := fl.Parent().FieldByName("Category").String()
+		return tax.AllowsActualExpenses(category)
+	})
+}
+
+func registerCreditValidation(v *validator.Validate) error {
+	return v.RegisterValidation("credit_custom_validation", func(fl validator.FieldLevel) bool {
+		credit, ok := fl.Parent().FieldByName(fl.StructFieldName()).Interface().(decimal.Decimal)
+		if !ok {
+			return false
+		}
+
+		amount, ok := fl.Parent().FieldByName("Amount").Interface().(decimal.Decimal)
+		if !ok {
+			return false
+		}
+
+		return !credit.IsNegative() && credit.LessThanOrEqual(amount)
 	})
 }
@@ -1,6 +1,61 @@
 package db
 
-import "database/sql"
+import (
+	"database/sql"
+	"fmt"
+)
+
+const seedDeductionsQuery = `
+	INSERT INTO "deductions" ("type", "amount", "min_amount", "max_amount", "income_percent", "per_person", "max_count")
+	VALUES
+		('personal', 60000.00, 10000.00, 100000.00, NULL, FALSE, NULL),
+		('donation', 100000.00, 0.00, 100000.00, 10.00, FALSE, NULL),
+		('k-receipt', 50000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('spouse', 60000.00, 0.00, 100000.00, NULL, TRUE, 1),
+		('child', 30000.00, 0.00, 100000.00, NULL, TRUE, NULL),
+		('child-2018', 60000.00, 0.00, 100000.00, NULL, TRUE, NULL),
+		('parent', 30000.00, 0.00, 100000.00, NULL, TRUE, 4),
+		('disabled', 60000.00, 0.00, 100000.00, NULL, TRUE, NULL),
+		('prenatal', 60000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('life-insurance', 100000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('health-insurance', 25000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('parent-health-insurance', 15000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('social-security', 9000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('provident-fund', 500000.00, 0.00, 500000.00, 15.00, FALSE, NULL),
+		('ssf', 200000.00, 0.00, 500000.00, 30.00, FALSE, NULL),
+		('rmf', 500000.00, 0.00, 500000.00, 30.00, FALSE, NULL),
+		('thai-esg', 100000.00, 0.00, 500000.00, 30.00, FALSE, NULL),
+		('home-loan-interest', 100000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('e-receipt', 50000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+		('donation-education', 100000.00, 0.00, 100000.00, 10.00, FALSE, NULL)
+	ON CONFLICT (type) DO UPDATE
+	SET
+		min_amount = EXCLUDED.min_amount,
+		max_amount = EXCLUDED.max_amount,
+		income_percent = EXCLUDED.income_percent,
+		per_person = EXCLUDED.per_person,
+		max_count = EXCLUDED.max_count
+`
+
+// allowanceTypes are the deduction types added after the initial schema.
+// ALTER TYPE ... ADD VALUE must run outside the statement that uses them.
+var allowanceTypes = []string{
+	"spouse", "child", "child-2018", "parent", "disabled", "prenatal",
+	"life-insurance", "health-insurance", "parent-health-insurance",
+	"social-security", "provident-fund", "ssf", "rmf", "thai-esg",
+	"home-loan-interest", "e-receipt", "donation-education",
+}
+
+const seedTaxBracketsQuery = `
+	INSERT INTO "tax_brackets" ("tax_year", "min_total_income", "max_total_income", "tax_rate")
+	VALUES
+		(2017, 0.00, 150000.00, 0.00),
+		(2017, 150000.00, 500000.00, 10.00),
+		(2017, 500000.00, 1000000.00, 15.00),
+		(2017, 1000000.00, 2000000.00, 20.00),
+		(2017, 2000000.00, NULL, 35.00)
+	ON CONFLICT ("tax_year", "min_total_income") DO NOTHING
+`
 
 func PrepareDatabase(db *sql.DB) error {
 	_, err := db.Exec(`
@@ -19,6 +74,8 @@ func PrepareDatabase(db *sql.DB) error {
 			"id" SERIAL PRIMARY KEY,
 			"type" deduction_type NOT NULL,
 			"amount" DECIMAL(10, 2) NOT NULL,
+			"min_amount" DECIMAL(10, 2) NOT NULL DEFAULT 0,
+			"max_amount" DECIMAL(10, 2) NOT NULL DEFAULT 0,
 			"created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			"updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			CONSTRAINT unique_deduction_type UNIQUE ("type")
@@ -29,36 +86,187 @@ func PrepareDatabase(db *sql.DB) error {
 	}
 
 	_, err = db.Exec(`
-		INSERT INTO "deductions" ("type", "amount")
-		VALUES
-			('personal', 60000.00),
-			('donation', 100000.00),
-			('k-receipt', 50000.00)
-		ON CONFLICT (type) DO NOTHING
+		ALTER TABLE "deductions"
+			ADD COLUMN IF NOT EXISTS "min_amount" DECIMAL(10, 2) NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS "max_amount" DECIMAL(10, 2) NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS "tax_brackets" (
+			"id" SERIAL PRIMARY KEY,
+			"tax_year" INTEGER NOT NULL,
+			"min_total_income" DECIMAL(14, 2) NOT NULL,
+			"max_total_income" DECIMAL(14, 2),
+			"tax_rate" DECIMAL(5, 2) NOT NULL,
+			"created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			"updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CONSTRAINT unique_tax_bracket UNIQUE ("tax_year", "min_total_income")
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		ALTER TABLE "tax_brackets" DROP COLUMN IF EXISTS "tax_level"
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS "deduction_changes" (
+			"id" SERIAL PRIMARY KEY,
+			"deduction_type" deduction_type NOT NULL,
+			"old_amount" DECIMAL(10, 2) NOT NULL,
+			"new_amount" DECIMAL(10, 2) NOT NULL,
+			"changed_by" VARCHAR(255) NOT NULL,
+			"request_id" VARCHAR(255) NOT NULL,
+			"client_ip" VARCHAR(64) NOT NULL,
+			"changed_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS "deduction_changes_type_changed_at_idx"
+		ON "deduction_changes" ("deduction_type", "changed_at")
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS "deduction_amounts" (
+			"id" SERIAL PRIMARY KEY,
+			"deduction_type" deduction_type NOT NULL,
+			"amount" DECIMAL(10, 2) NOT NULL,
+			"effective_from" DATE NOT NULL,
+			"effective_to" DATE,
+			"created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CONSTRAINT effective_range CHECK ("effective_to" IS NULL OR "effective_to" >= "effective_from")
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS "deduction_amounts_type_effective_from_idx"
+		ON "deduction_amounts" ("deduction_type", "effective_from")
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		ALTER TABLE "deduction_changes"
+			ADD COLUMN IF NOT EXISTS "effective_from" DATE,
+			ADD COLUMN IF NOT EXISTS "effective_to" DATE
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE "deduction_changes" SET "effective_from" = "changed_at"::date WHERE "effective_from" IS NULL
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		ALTER TABLE "deduction_changes" ALTER COLUMN "effective_from" SET NOT NULL
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, allowanceType := range allowanceTypes {
+		_, err = db.Exec(fmt.Sprintf(`ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS '%s'`, allowanceType))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec(`
+		ALTER TABLE "deductions"
+			ADD COLUMN IF NOT EXISTS "income_percent" DECIMAL(5, 2),
+			ADD COLUMN IF NOT EXISTS "per_person" BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN IF NOT EXISTS "max_count" INTEGER
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS "refund_claims" (
+			"id" SERIAL PRIMARY KEY,
+			"taxpayer_id" VARCHAR(255) NOT NULL,
+			"tax_year" INTEGER NOT NULL,
+			"amount" DECIMAL(14, 2) NOT NULL,
+			"filed_at" DATE NOT NULL,
+			"status" VARCHAR(32) NOT NULL DEFAULT 'submitted',
+			"interest" DECIMAL(14, 2) NOT NULL DEFAULT 0,
+			"paid_at" DATE,
+			"updated_by" VARCHAR(255) NOT NULL DEFAULT '',
+			"created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			"updated_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			CONSTRAINT refund_status CHECK ("status" IN ('submitted', 'under_review', 'paid'))
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS "refund_claims_status_idx"
+		ON "refund_claims" ("status", "id")
 	`)
 	if err != nil {
 		return err
 	}
 
+	_, err = db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS "refund_claims_taxpayer_tax_year_idx"
+		ON "refund_claims" ("taxpayer_id", "tax_year")
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(seedDeductionsQuery)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(seedTaxBracketsQuery)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func ResetDatabase(db *sql.DB) error {
 	_, err := db.Exec(`
-		TRUNCATE TABLE "deductions" RESTART IDENTITY CASCADE
+		TRUNCATE TABLE "deductions", "deduction_changes", "deduction_amounts", "tax_brackets", "refund_claims" RESTART IDENTITY CASCADE
 	`)
 	if err != nil {
 		return err
 	}
 
-	_, err = db.Exec(`
-		INSERT INTO "deductions" ("type", "amount")
-		VALUES
-			('personal', 60000.00),
-			('donation', 100000.00),
-			('k-receipt', 50000.00)
-		ON CONFLICT (type) DO NOTHING
-	`)
+	_, err = db.Exec(seedDeductionsQuery)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(seedTaxBracketsQuery)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "tax_brackets";
\ No newline at end of file
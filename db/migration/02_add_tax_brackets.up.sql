@@ -0,0 +1,22 @@
+-- Table Definition
+CREATE TABLE IF NOT EXISTS "tax_brackets" (
+    "id" SERIAL PRIMARY KEY,
+    "tax_year" INTEGER NOT NULL,
+    "min_total_income" DECIMAL(14, 2) NOT NULL,
+    "max_total_income" DECIMAL(14, 2),
+    "tax_rate" DECIMAL(5, 2) NOT NULL,
+    "tax_level" VARCHAR(64) NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_tax_bracket UNIQUE ("tax_year", "min_total_income")
+    );
+
+-- Progressive rates in force since tax year 2017
+INSERT INTO "tax_brackets" ("tax_year", "min_total_income", "max_total_income", "tax_rate", "tax_level")
+VALUES
+    (2017, 0.00, 150000.00, 0.00, '0-150,000'),
+    (2017, 150000.00, 500000.00, 10.00, '150,001-500,000'),
+    (2017, 500000.00, 1000000.00, 15.00, '500,001-1,000,000'),
+    (2017, 1000000.00, 2000000.00, 20.00, '1,000,001-2,000,000'),
+    (2017, 2000000.00, NULL, 35.00, '2,000,001 ขึ้นไป')
+ON CONFLICT ("tax_year", "min_total_income") DO NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE "tax_brackets" ADD COLUMN IF NOT EXISTS "tax_level" VARCHAR(64);
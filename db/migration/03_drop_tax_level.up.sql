@@ -0,0 +1,2 @@
+-- Tax level labels are generated from the bracket ranges
+ALTER TABLE "tax_brackets" DROP COLUMN IF EXISTS "tax_level";
@@ -0,0 +1,3 @@
+ALTER TABLE "deductions"
+    DROP COLUMN IF EXISTS "min_amount",
+    DROP COLUMN IF EXISTS "max_amount";
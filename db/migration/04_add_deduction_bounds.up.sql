@@ -0,0 +1,14 @@
+ALTER TABLE "deductions"
+    ADD COLUMN IF NOT EXISTS "min_amount" DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "max_amount" DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Bounds an admin may set each deduction to
+INSERT INTO "deductions" ("type", "amount", "min_amount", "max_amount")
+VALUES
+    ('personal', 60000.00, 10000.00, 100000.00),
+    ('donation', 100000.00, 0.00, 100000.00),
+    ('k-receipt', 50000.00, 0.00, 100000.00)
+ON CONFLICT (type) DO UPDATE
+SET
+    min_amount = EXCLUDED.min_amount,
+    max_amount = EXCLUDED.max_amount;
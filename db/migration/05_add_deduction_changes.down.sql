@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "deduction_changes";
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Append-only log of admin changes to deductions
+CREATE TABLE IF NOT EXISTS "deduction_changes" (
+    "id" SERIAL PRIMARY KEY,
+    "deduction_type" deduction_type NOT NULL,
+    "old_amount" DECIMAL(10, 2) NOT NULL,
+    "new_amount" DECIMAL(10, 2) NOT NULL,
+    "changed_by" VARCHAR(255) NOT NULL,
+    "request_id" VARCHAR(255) NOT NULL,
+    "client_ip" VARCHAR(64) NOT NULL,
+    "changed_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS "deduction_changes_type_changed_at_idx"
+    ON "deduction_changes" ("deduction_type", "changed_at");
@@ -0,0 +1,5 @@
+ALTER TABLE "deduction_changes"
+    DROP COLUMN IF EXISTS "effective_from",
+    DROP COLUMN IF EXISTS "effective_to";
+
+DROP TABLE IF EXISTS "deduction_amounts";
@@ -0,0 +1,22 @@
+-- Effective-dated deduction amounts; deductions.amount is the baseline
+-- used on dates no row covers
+CREATE TABLE IF NOT EXISTS "deduction_amounts" (
+    "id" SERIAL PRIMARY KEY,
+    "deduction_type" deduction_type NOT NULL,
+    "amount" DECIMAL(10, 2) NOT NULL,
+    "effective_from" DATE NOT NULL,
+    "effective_to" DATE,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT effective_range CHECK ("effective_to" IS NULL OR "effective_to" >= "effective_from")
+    );
+
+CREATE INDEX IF NOT EXISTS "deduction_amounts_type_effective_from_idx"
+    ON "deduction_amounts" ("deduction_type", "effective_from");
+
+ALTER TABLE "deduction_changes"
+    ADD COLUMN IF NOT EXISTS "effective_from" DATE,
+    ADD COLUMN IF NOT EXISTS "effective_to" DATE;
+
+UPDATE "deduction_changes" SET "effective_from" = "changed_at"::date WHERE "effective_from" IS NULL;
+
+ALTER TABLE "deduction_changes" ALTER COLUMN "effective_from" SET NOT NULL;
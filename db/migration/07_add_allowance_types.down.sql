@@ -0,0 +1,2 @@
+-- PostgreSQL cannot drop enum values; the rows using them are removed by
+-- 08_add_allowance_rules.down.sql
@@ -0,0 +1,19 @@
+-- Allowance types beyond personal, donation and k-receipt. New enum values
+-- cannot be used in the transaction that adds them, so the rules are seeded
+-- by the next migration.
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'spouse';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'child';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'child-2018';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'parent';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'disabled';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'prenatal';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'life-insurance';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'health-insurance';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'parent-health-insurance';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'social-security';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'provident-fund';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'ssf';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'rmf';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'thai-esg';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'home-loan-interest';
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'e-receipt';
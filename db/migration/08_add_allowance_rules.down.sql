@@ -0,0 +1,8 @@
+DELETE FROM "deduction_amounts" WHERE "deduction_type" NOT IN ('personal', 'donation', 'k-receipt');
+DELETE FROM "deduction_changes" WHERE "deduction_type" NOT IN ('personal', 'donation', 'k-receipt');
+DELETE FROM "deductions" WHERE "type" NOT IN ('personal', 'donation', 'k-receipt');
+
+ALTER TABLE "deductions"
+    DROP COLUMN IF EXISTS "income_percent",
+    DROP COLUMN IF EXISTS "per_person",
+    DROP COLUMN IF EXISTS "max_count";
@@ -0,0 +1,36 @@
+-- Cap rules of each allowance: amount is the cap, or the amount per
+-- dependant when per_person is set, further limited to income_percent of
+-- the total income and to max_count dependants when given
+ALTER TABLE "deductions"
+    ADD COLUMN IF NOT EXISTS "income_percent" DECIMAL(5, 2),
+    ADD COLUMN IF NOT EXISTS "per_person" BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS "max_count" INTEGER;
+
+INSERT INTO "deductions" ("type", "amount", "min_amount", "max_amount", "income_percent", "per_person", "max_count")
+VALUES
+    ('personal', 60000.00, 10000.00, 100000.00, NULL, FALSE, NULL),
+    ('donation', 100000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('k-receipt', 50000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('spouse', 60000.00, 0.00, 100000.00, NULL, TRUE, 1),
+    ('child', 30000.00, 0.00, 100000.00, NULL, TRUE, NULL),
+    ('child-2018', 60000.00, 0.00, 100000.00, NULL, TRUE, NULL),
+    ('parent', 30000.00, 0.00, 100000.00, NULL, TRUE, 4),
+    ('disabled', 60000.00, 0.00, 100000.00, NULL, TRUE, NULL),
+    ('prenatal', 60000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('life-insurance', 100000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('health-insurance', 25000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('parent-health-insurance', 15000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('social-security', 9000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('provident-fund', 500000.00, 0.00, 500000.00, 15.00, FALSE, NULL),
+    ('ssf', 200000.00, 0.00, 500000.00, 30.00, FALSE, NULL),
+    ('rmf', 500000.00, 0.00, 500000.00, 30.00, FALSE, NULL),
+    ('thai-esg', 100000.00, 0.00, 500000.00, 30.00, FALSE, NULL),
+    ('home-loan-interest', 100000.00, 0.00, 100000.00, NULL, FALSE, NULL),
+    ('e-receipt', 50000.00, 0.00, 100000.00, NULL, FALSE, NULL)
+ON CONFLICT (type) DO UPDATE
+SET
+    min_amount = EXCLUDED.min_amount,
+    max_amount = EXCLUDED.max_amount,
+    income_percent = EXCLUDED.income_percent,
+    per_person = EXCLUDED.per_person,
+    max_count = EXCLUDED.max_count;
@@ -0,0 +1,2 @@
+-- PostgreSQL cannot drop enum values; the rows using them are removed by
+-- 10_add_donation_income_percent.down.sql
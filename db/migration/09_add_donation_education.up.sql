@@ -0,0 +1,2 @@
+-- Education and hospital donations, deducted at twice the amount donated
+ALTER TYPE deduction_type ADD VALUE IF NOT EXISTS 'donation-education';
@@ -0,0 +1,5 @@
+DELETE FROM "deduction_amounts" WHERE "deduction_type" = 'donation-education';
+DELETE FROM "deduction_changes" WHERE "deduction_type" = 'donation-education';
+DELETE FROM "deductions" WHERE "type" = 'donation-education';
+
+UPDATE "deductions" SET "income_percent" = NULL WHERE "type" = 'donation';
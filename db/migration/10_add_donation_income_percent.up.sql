@@ -0,0 +1,12 @@
+-- Donations are capped at 10% of the income after every other allowance
+INSERT INTO "deductions" ("type", "amount", "min_amount", "max_amount", "income_percent", "per_person", "max_count")
+VALUES
+    ('donation', 100000.00, 0.00, 100000.00, 10.00, FALSE, NULL),
+    ('donation-education', 100000.00, 0.00, 100000.00, 10.00, FALSE, NULL)
+ON CONFLICT (type) DO UPDATE
+SET
+    min_amount = EXCLUDED.min_amount,
+    max_amount = EXCLUDED.max_amount,
+    income_percent = EXCLUDED.income_percent,
+    per_person = EXCLUDED.per_person,
+    max_count = EXCLUDED.max_count;
@@ -0,0 +1,22 @@
+-- Refunds claimed from calculations and followed up until paid
+CREATE TABLE IF NOT EXISTS "refund_claims" (
+    "id" SERIAL PRIMARY KEY,
+    "taxpayer_id" VARCHAR(255) NOT NULL,
+    "tax_year" INTEGER NOT NULL,
+    "amount" DECIMAL(14, 2) NOT NULL,
+    "filed_at" DATE NOT NULL,
+    "status" VARCHAR(32) NOT NULL DEFAULT 'submitted',
+    "interest" DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    "paid_at" DATE,
+    "updated_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT refund_status CHECK ("status" IN ('submitted', 'under_review', 'paid'))
+    );
+
+CREATE INDEX IF NOT EXISTS "refund_claims_status_idx"
+    ON "refund_claims" ("status", "id");
+
+-- One refund per taxpayer and tax year
+CREATE UNIQUE INDEX IF NOT EXISTS "refund_claims_taxpayer_tax_year_idx"
+    ON "refund_claims" ("taxpayer_id", "tax_year");
@@ -7,6 +7,7 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/danyouknowme/assessment-tax/db"
 	gomock "github.com/golang/mock/gomock"
@@ -35,19 +36,168 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// CreateRefundClaim mocks base method.
+func (m *MockStore) CreateRefundClaim(ctx context.Context, arg db.CreateRefundClaimParams) (*db.RefundClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefundClaim", ctx, arg)
+	ret0, _ := ret[0].(*db.RefundClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefundClaim indicates an expected call of CreateRefundClaim.
+func (mr *MockStoreMockRecorder) CreateRefundClaim(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefundClaim", reflect.TypeOf((*MockStore)(nil).CreateRefundClaim), ctx, arg)
+}
+
+// CreateTaxBrackets mocks base method.
+func (m *MockStore) CreateTaxBrackets(ctx context.Context, taxYear int, brackets []db.TaxBracket) ([]db.TaxBracket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaxBrackets", ctx, taxYear, brackets)
+	ret0, _ := ret[0].([]db.TaxBracket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTaxBrackets indicates an expected call of CreateTaxBrackets.
+func (mr *MockStoreMockRecorder) CreateTaxBrackets(ctx, taxYear, brackets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaxBrackets", reflect.TypeOf((*MockStore)(nil).CreateTaxBrackets), ctx, taxYear, brackets)
+}
+
+// DeleteTaxBrackets mocks base method.
+func (m *MockStore) DeleteTaxBrackets(ctx context.Context, taxYear int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaxBrackets", ctx, taxYear)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaxBrackets indicates an expected call of DeleteTaxBrackets.
+func (mr *MockStoreMockRecorder) DeleteTaxBrackets(ctx, taxYear interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaxBrackets", reflect.TypeOf((*MockStore)(nil).DeleteTaxBrackets), ctx, taxYear)
+}
+
 // GetAllDeductions mocks base method.
-func (m *MockStore) GetAllDeductions(ctx context.Context) ([]db.Deduction, error) {
+func (m *MockStore) GetAllDeductions(ctx context.Context, asOf time.Time) ([]db.Deduction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllDeductions", ctx)
+	ret := m.ctrl.Call(m, "GetAllDeductions", ctx, asOf)
 	ret0, _ := ret[0].([]db.Deduction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllDeductions indicates an expected call of GetAllDeductions.
-func (mr *MockStoreMockRecorder) GetAllDeductions(ctx interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetAllDeductions(ctx, asOf interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllDeductions", reflect.TypeOf((*MockStore)(nil).GetAllDeductions), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllDeductions", reflect.TypeOf((*MockStore)(nil).GetAllDeductions), ctx, asOf)
+}
+
+// GetDeductionByType mocks base method.
+func (m *MockStore) GetDeductionByType(ctx context.Context, deductionType string) (*db.Deduction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeductionByType", ctx, deductionType)
+	ret0, _ := ret[0].(*db.Deduction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeductionByType indicates an expected call of GetDeductionByType.
+func (mr *MockStoreMockRecorder) GetDeductionByType(ctx, deductionType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeductionByType", reflect.TypeOf((*MockStore)(nil).GetDeductionByType), ctx, deductionType)
+}
+
+// GetRefundClaim mocks base method.
+func (m *MockStore) GetRefundClaim(ctx context.Context, id int) (*db.RefundClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundClaim", ctx, id)
+	ret0, _ := ret[0].(*db.RefundClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundClaim indicates an expected call of GetRefundClaim.
+func (mr *MockStoreMockRecorder) GetRefundClaim(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundClaim", reflect.TypeOf((*MockStore)(nil).GetRefundClaim), ctx, id)
+}
+
+// GetTaxBracketsByYear mocks base method.
+func (m *MockStore) GetTaxBracketsByYear(ctx context.Context, taxYear int) ([]db.TaxBracket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxBracketsByYear", ctx, taxYear)
+	ret0, _ := ret[0].([]db.TaxBracket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxBracketsByYear indicates an expected call of GetTaxBracketsByYear.
+func (mr *MockStoreMockRecorder) GetTaxBracketsByYear(ctx, taxYear interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxBracketsByYear", reflect.TypeOf((*MockStore)(nil).GetTaxBracketsByYear), ctx, taxYear)
+}
+
+// ListDeductionChanges mocks base method.
+func (m *MockStore) ListDeductionChanges(ctx context.Context, arg db.ListDeductionChangesParams) ([]db.DeductionChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeductionChanges", ctx, arg)
+	ret0, _ := ret[0].([]db.DeductionChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeductionChanges indicates an expected call of ListDeductionChanges.
+func (mr *MockStoreMockRecorder) ListDeductionChanges(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeductionChanges", reflect.TypeOf((*MockStore)(nil).ListDeductionChanges), ctx, arg)
+}
+
+// ListRefundClaims mocks base method.
+func (m *MockStore) ListRefundClaims(ctx context.Context, arg db.ListRefundClaimsParams) ([]db.RefundClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefundClaims", ctx, arg)
+	ret0, _ := ret[0].([]db.RefundClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRefundClaims indicates an expected call of ListRefundClaims.
+func (mr *MockStoreMockRecorder) ListRefundClaims(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefundClaims", reflect.TypeOf((*MockStore)(nil).ListRefundClaims), ctx, arg)
+}
+
+// ListTaxBrackets mocks base method.
+func (m *MockStore) ListTaxBrackets(ctx context.Context) ([]db.TaxBracket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTaxBrackets", ctx)
+	ret0, _ := ret[0].([]db.TaxBracket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTaxBrackets indicates an expected call of ListTaxBrackets.
+func (mr *MockStoreMockRecorder) ListTaxBrackets(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTaxBrackets", reflect.TypeOf((*MockStore)(nil).ListTaxBrackets), ctx)
+}
+
+// ReplaceTaxBrackets mocks base method.
+func (m *MockStore) ReplaceTaxBrackets(ctx context.Context, taxYear int, brackets []db.TaxBracket) ([]db.TaxBracket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceTaxBrackets", ctx, taxYear, brackets)
+	ret0, _ := ret[0].([]db.TaxBracket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceTaxBrackets indicates an expected call of ReplaceTaxBrackets.
+func (mr *MockStoreMockRecorder) ReplaceTaxBrackets(ctx, taxYear, brackets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTaxBrackets", reflect.TypeOf((*MockStore)(nil).ReplaceTaxBrackets), ctx, taxYear, brackets)
 }
 
 // UpdateDeductionByType mocks base method.
@@ -64,3 +214,18 @@ func (mr *MockStoreMockRecorder) UpdateDeductionByType(ctx, deductionType, arg i
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeductionByType", reflect.TypeOf((*MockStore)(nil).UpdateDeductionByType), ctx, deductionType, arg)
 }
+
+// UpdateRefundClaimStatus mocks base method.
+func (m *MockStore) UpdateRefundClaimStatus(ctx context.Context, id int, arg db.UpdateRefundClaimStatusParams) (*db.RefundClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefundClaimStatus", ctx, id, arg)
+	ret0, _ := ret[0].(*db.RefundClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRefundClaimStatus indicates an expected call of UpdateRefundClaimStatus.
+func (mr *MockStoreMockRecorder) UpdateRefundClaimStatus(ctx, id, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefundClaimStatus", reflect.TypeOf((*MockStore)(nil).UpdateRefundClaimStatus), ctx, id, arg)
+}
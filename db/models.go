@@ -1,10 +1,104 @@
 package db
 
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Deduction is the current value of a deduction type together with the
+// bounds an admin may set it to. Amount caps the allowance, or is the amount
+// per dependant when PerPerson is set. A valid IncomePercent further caps it
+// to that percentage of the total income, and a non-zero MaxCount limits the
+// number of dependants.
 type Deduction struct {
-	Type   string
-	Amount float64
+	Type          string
+	Amount        decimal.Decimal
+	MinAmount     decimal.Decimal
+	MaxAmount     decimal.Decimal
+	IncomePercent decimal.NullDecimal
+	PerPerson     bool
+	MaxCount      int
 }
 
+// UpdateDeductionParams carries the new amount, the dates it is in force
+// and who asked for it, which is recorded in the deduction change log. An
+// invalid EffectiveTo keeps the amount in force until it is superseded.
 type UpdateDeductionParams struct {
-	Amount float64 `json:"amount"`
+	Amount        decimal.Decimal `json:"amount"`
+	EffectiveFrom time.Time       `json:"-"`
+	EffectiveTo   sql.NullTime    `json:"-"`
+	ChangedBy     string          `json:"-"`
+	RequestID     string          `json:"-"`
+	ClientIP      string          `json:"-"`
+}
+
+// DeductionChange is one entry of the append-only deduction change log.
+type DeductionChange struct {
+	DeductionType string
+	OldAmount     decimal.Decimal
+	NewAmount     decimal.Decimal
+	EffectiveFrom time.Time
+	EffectiveTo   sql.NullTime
+	ChangedBy     string
+	RequestID     string
+	ClientIP      string
+	ChangedAt     time.Time
+}
+
+type ListDeductionChangesParams struct {
+	DeductionType string
+	From          sql.NullTime
+	To            sql.NullTime
+}
+
+// TaxBracket is one step of the progressive tax schedule of a tax year.
+// TaxRate is a percentage and an invalid MaxTotalIncome means the bracket
+// has no upper bound.
+type TaxBracket struct {
+	TaxYear        int
+	MinTotalIncome decimal.Decimal
+	MaxTotalIncome decimal.NullDecimal
+	TaxRate        decimal.Decimal
+}
+
+// RefundClaim is a tax refund claimed from a calculation and followed up
+// until it is paid. Interest is the statutory interest on a late refund,
+// fixed when the claim is paid.
+type RefundClaim struct {
+	ID         int
+	TaxpayerID string
+	TaxYear    int
+	Amount     decimal.Decimal
+	FiledAt    time.Time
+	Status     string
+	Interest   decimal.Decimal
+	PaidAt     sql.NullTime
+	UpdatedBy  string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type CreateRefundClaimParams struct {
+	TaxpayerID string
+	TaxYear    int
+	Amount     decimal.Decimal
+	FiledAt    time.Time
+	Status     string
+}
+
+type ListRefundClaimsParams struct {
+	Status sql.NullString
+}
+
+// UpdateRefundClaimStatusParams moves a claim from the status FromStatus
+// to Status, recording who did it and, once paid, when and the interest
+// owed.
+type UpdateRefundClaimStatusParams struct {
+	FromStatus string
+	Status     string
+	PaidAt     sql.NullTime
+	Interest   decimal.Decimal
+	UpdatedBy  string
 }
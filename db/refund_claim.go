@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrRefundClaimStatusChanged is returned when a refund claim is no longer
+// in the status it is being moved from.
+var ErrRefundClaimStatusChanged = errors.New("refund claim status changed")
+
+// ErrRefundClaimExists is returned when the taxpayer has already claimed the
+// refund of the tax year.
+var ErrRefundClaimExists = errors.New("refund claim already exists")
+
+const refundClaimColumns = `
+	id, taxpayer_id, tax_year, amount, filed_at, status, interest, paid_at, updated_by, created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRefundClaim(row rowScanner) (*RefundClaim, error) {
+	var c RefundClaim
+	err := row.Scan(&c.ID, &c.TaxpayerID, &c.TaxYear, &c.Amount, &c.FiledAt, &c.Status, &c.Interest, &c.PaidAt, &c.UpdatedBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// CreateRefundClaim records a claim. It returns ErrRefundClaimExists when
+// the taxpayer already claimed the refund of the tax year.
+func (s *SQLStore) CreateRefundClaim(ctx context.Context, arg CreateRefundClaimParams) (*RefundClaim, error) {
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO refund_claims (taxpayer_id, tax_year, amount, filed_at, status)
+		VALUES ($1, $2, $3, $4::date, $5)
+		ON CONFLICT (taxpayer_id, tax_year) DO NOTHING
+		RETURNING `+refundClaimColumns,
+		arg.TaxpayerID, arg.TaxYear, arg.Amount, dateParam(arg.FiledAt), arg.Status)
+
+	claim, err := scanRefundClaim(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefundClaimExists
+	}
+
+	return claim, err
+}
+
+// GetRefundClaim returns the claim with id. It returns sql.ErrNoRows when
+// there is none.
+func (s *SQLStore) GetRefundClaim(ctx context.Context, id int) (*RefundClaim, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+refundClaimColumns+`
+		FROM refund_claims
+		WHERE id = $1
+	`, id)
+
+	return scanRefundClaim(row)
+}
+
+// ListRefundClaims returns the claims, oldest first, optionally only those
+// in arg.Status.
+func (s *SQLStore) ListRefundClaims(ctx context.Context, arg ListRefundClaimsParams) ([]RefundClaim, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+refundClaimColumns+`
+		FROM refund_claims
+		WHERE $1::text IS NULL OR status = $1
+		ORDER BY id
+	`, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []RefundClaim
+	for rows.Next() {
+		c, err := scanRefundClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		claims = append(claims, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// UpdateRefundClaimStatus moves the claim with id from arg.FromStatus to
+// arg.Status. It returns sql.ErrNoRows when there is no such claim and
+// ErrRefundClaimStatusChanged when it has moved on in the meantime.
+func (s *SQLStore) UpdateRefundClaimStatus(ctx context.Context, id int, arg UpdateRefundClaimStatusParams) (*RefundClaim, error) {
+	var claim *RefundClaim
+	err := s.execTx(ctx, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRowContext(ctx, `
+			SELECT status FROM refund_claims WHERE id = $1 FOR UPDATE
+		`, id).Scan(&status)
+		if err != nil {
+			return err
+		}
+
+		if status != arg.FromStatus {
+			return ErrRefundClaimStatusChanged
+		}
+
+		row := tx.QueryRowContext(ctx, `
+			UPDATE refund_claims
+			SET status = $2, paid_at = $3::date, interest = $4, updated_by = $5, updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+refundClaimColumns,
+			id, arg.Status, nullDateParam(arg.PaidAt), arg.Interest, arg.UpdatedBy)
+
+		claim, err = scanRefundClaim(row)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claim, nil
+}
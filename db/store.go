@@ -4,11 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Store interface {
-	GetAllDeductions(ctx context.Context) ([]Deduction, error)
+	GetAllDeductions(ctx context.Context, asOf time.Time) ([]Deduction, error)
+	GetDeductionByType(ctx context.Context, deductionType string) (*Deduction, error)
 	UpdateDeductionByType(ctx context.Context, deductionType string, arg UpdateDeductionParams) (*Deduction, error)
+	ListDeductionChanges(ctx context.Context, arg ListDeductionChangesParams) ([]DeductionChange, error)
+	GetTaxBracketsByYear(ctx context.Context, taxYear int) ([]TaxBracket, error)
+	ListTaxBrackets(ctx context.Context) ([]TaxBracket, error)
+	CreateTaxBrackets(ctx context.Context, taxYear int, brackets []TaxBracket) ([]TaxBracket, error)
+	ReplaceTaxBrackets(ctx context.Context, taxYear int, brackets []TaxBracket) ([]TaxBracket, error)
+	DeleteTaxBrackets(ctx context.Context, taxYear int) error
+	CreateRefundClaim(ctx context.Context, arg CreateRefundClaimParams) (*RefundClaim, error)
+	GetRefundClaim(ctx context.Context, id int) (*RefundClaim, error)
+	ListRefundClaims(ctx context.Context, arg ListRefundClaimsParams) ([]RefundClaim, error)
+	UpdateRefundClaimStatus(ctx context.Context, id int, arg UpdateRefundClaimStatusParams) (*RefundClaim, error)
 }
 
 type SQLStore struct {
@@ -21,9 +35,45 @@ func NewStore(conn *sql.DB) Store {
 	}
 }
 
-func (s *SQLStore) GetAllDeductions(ctx context.Context) ([]Deduction, error) {
+// execTx runs fn inside a database transaction, rolling back when fn fails.
+func (s *SQLStore) execTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deductionsAsOfQuery selects every deduction with the amount in force on
+// the date $1: the latest scheduled amount covering that date, or the
+// baseline amount of the deductions table when none does.
+const deductionsAsOfQuery = `
+	SELECT d.type, COALESCE(a.amount, d.amount), d.min_amount, d.max_amount, d.income_percent, d.per_person, COALESCE(d.max_count, 0)
+	FROM deductions d
+	LEFT JOIN LATERAL (
+		SELECT amount
+		FROM deduction_amounts
+		WHERE
+			deduction_type = d.type
+			AND effective_from <= $1::date
+			AND (effective_to IS NULL OR effective_to >= $1::date)
+		ORDER BY effective_from DESC, id DESC
+		LIMIT 1
+	) a ON true
+`
+
+// GetAllDeductions returns the deductions in force on asOf.
+func (s *SQLStore) GetAllDeductions(ctx context.Context, asOf time.Time) ([]Deduction, error) {
 	var deductions []Deduction
-	rows, err := s.db.QueryContext(ctx, "SELECT type, amount FROM deductions")
+	rows, err := s.db.QueryContext(ctx, deductionsAsOfQuery+" ORDER BY d.id", dateParam(asOf))
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +81,7 @@ func (s *SQLStore) GetAllDeductions(ctx context.Context) ([]Deduction, error) {
 
 	for rows.Next() {
 		var d Deduction
-		err := rows.Scan(&d.Type, &d.Amount)
+		err := rows.Scan(&d.Type, &d.Amount, &d.MinAmount, &d.MaxAmount, &d.IncomePercent, &d.PerPerson, &d.MaxCount)
 		if err != nil {
 			fmt.Println("Error scanning row: ", err)
 			return nil, err
@@ -43,25 +93,111 @@ func (s *SQLStore) GetAllDeductions(ctx context.Context) ([]Deduction, error) {
 	return deductions, nil
 }
 
-func (s *SQLStore) UpdateDeductionByType(ctx context.Context, deductionType string, arg UpdateDeductionParams) (*Deduction, error) {
-	stmt, err := s.db.Prepare(`
-		UPDATE deductions
-		SET
-		    amount = COALESCE($1, amount),
-			updated_at = NOW()
-		WHERE 
-			type = $2
-		RETURNING type, amount
-	`)
+// GetDeductionByType returns the deduction in force today. It returns
+// sql.ErrNoRows for unknown deduction types.
+func (s *SQLStore) GetDeductionByType(ctx context.Context, deductionType string) (*Deduction, error) {
+	var d Deduction
+	err := s.db.QueryRowContext(ctx, deductionsAsOfQuery+" WHERE d.type::text = $2", dateParam(time.Now()), deductionType).
+		Scan(&d.Type, &d.Amount, &d.MinAmount, &d.MaxAmount, &d.IncomePercent, &d.PerPerson, &d.MaxCount)
 	if err != nil {
 		return nil, err
 	}
 
+	return &d, nil
+}
+
+// UpdateDeductionByType schedules a new amount from arg.EffectiveFrom until
+// arg.EffectiveTo, or indefinitely, and appends the change to
+// deduction_changes within the same transaction. Earlier amounts are kept
+// so calculations as of a past date stay reproducible.
+func (s *SQLStore) UpdateDeductionByType(ctx context.Context, deductionType string, arg UpdateDeductionParams) (*Deduction, error) {
 	var d Deduction
-	err = stmt.QueryRowContext(ctx, arg.Amount, deductionType).Scan(&d.Type, &d.Amount)
+	err := s.execTx(ctx, func(tx *sql.Tx) error {
+		var oldAmount decimal.Decimal
+		err := tx.QueryRowContext(ctx, deductionsAsOfQuery+" WHERE d.type::text = $2 FOR UPDATE OF d", dateParam(arg.EffectiveFrom), deductionType).
+			Scan(&d.Type, &oldAmount, &d.MinAmount, &d.MaxAmount, &d.IncomePercent, &d.PerPerson, &d.MaxCount)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO deduction_amounts (deduction_type, amount, effective_from, effective_to)
+			VALUES ($1, $2, $3::date, $4::date)
+		`, d.Type, arg.Amount, dateParam(arg.EffectiveFrom), nullDateParam(arg.EffectiveTo))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE deductions SET updated_at = NOW() WHERE type = $1
+		`, d.Type)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO deduction_changes (deduction_type, old_amount, new_amount, effective_from, effective_to, changed_by, request_id, client_ip)
+			VALUES ($1, $2, $3, $4::date, $5::date, $6, $7, $8)
+		`, d.Type, oldAmount, arg.Amount, dateParam(arg.EffectiveFrom), nullDateParam(arg.EffectiveTo), arg.ChangedBy, arg.RequestID, arg.ClientIP)
+		if err != nil {
+			return err
+		}
+
+		d.Amount = arg.Amount
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &d, nil
 }
+
+// ListDeductionChanges returns the changes of one deduction type, oldest
+// first, optionally limited to changes made in [From, To).
+func (s *SQLStore) ListDeductionChanges(ctx context.Context, arg ListDeductionChangesParams) ([]DeductionChange, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT deduction_type, old_amount, new_amount, effective_from, effective_to, changed_by, request_id, client_ip, changed_at
+		FROM deduction_changes
+		WHERE
+			deduction_type::text = $1
+			AND ($2::timestamptz IS NULL OR changed_at >= $2)
+			AND ($3::timestamptz IS NULL OR changed_at < $3)
+		ORDER BY changed_at, id
+	`, arg.DeductionType, arg.From, arg.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []DeductionChange
+	for rows.Next() {
+		var c DeductionChange
+		err := rows.Scan(&c.DeductionType, &c.OldAmount, &c.NewAmount, &c.EffectiveFrom, &c.EffectiveTo, &c.ChangedBy, &c.RequestID, &c.ClientIP, &c.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// dateParam passes the calendar date of t to a ::date query parameter
+// without any time zone conversion.
+func dateParam(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func nullDateParam(t sql.NullTime) sql.NullString {
+	if !t.Valid {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: dateParam(t.Time), Valid: true}
+}
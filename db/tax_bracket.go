@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrTaxBracketsExist is returned when creating a bracket set for a tax
+// year that already has one.
+var ErrTaxBracketsExist = errors.New("tax brackets already exist")
+
+// GetTaxBracketsByYear returns the brackets in force for taxYear, which are
+// the ones of the latest tax year on or before it. It returns sql.ErrNoRows
+// when no such bracket set exists.
+func (s *SQLStore) GetTaxBracketsByYear(ctx context.Context, taxYear int) ([]TaxBracket, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tax_year, min_total_income, max_total_income, tax_rate
+		FROM tax_brackets
+		WHERE
+			tax_year = (SELECT MAX(tax_year) FROM tax_brackets WHERE tax_year <= $1)
+		ORDER BY min_total_income
+	`, taxYear)
+	if err != nil {
+		return nil, err
+	}
+
+	brackets, err := scanTaxBrackets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(brackets) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return brackets, nil
+}
+
+func (s *SQLStore) ListTaxBrackets(ctx context.Context) ([]TaxBracket, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tax_year, min_total_income, max_total_income, tax_rate
+		FROM tax_brackets
+		ORDER BY tax_year, min_total_income
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTaxBrackets(rows)
+}
+
+func (s *SQLStore) CreateTaxBrackets(ctx context.Context, taxYear int, brackets []TaxBracket) ([]TaxBracket, error) {
+	var created []TaxBracket
+	err := s.execTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM tax_brackets WHERE tax_year = $1)
+		`, taxYear).Scan(&exists)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrTaxBracketsExist
+		}
+
+		created, err = insertTaxBrackets(ctx, tx, taxYear, brackets)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// ReplaceTaxBrackets swaps the whole bracket set of taxYear. It returns
+// sql.ErrNoRows when the tax year has no brackets to replace.
+func (s *SQLStore) ReplaceTaxBrackets(ctx context.Context, taxYear int, brackets []TaxBracket) ([]TaxBracket, error) {
+	var replaced []TaxBracket
+	err := s.execTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM tax_brackets WHERE tax_year = $1
+		`, taxYear)
+		if err != nil {
+			return err
+		}
+
+		if err := requireAffectedRows(result); err != nil {
+			return err
+		}
+
+		replaced, err = insertTaxBrackets(ctx, tx, taxYear, brackets)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// DeleteTaxBrackets removes the bracket set of taxYear. It returns
+// sql.ErrNoRows when the tax year has no brackets.
+func (s *SQLStore) DeleteTaxBrackets(ctx context.Context, taxYear int) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM tax_brackets WHERE tax_year = $1
+	`, taxYear)
+	if err != nil {
+		return err
+	}
+
+	return requireAffectedRows(result)
+}
+
+func insertTaxBrackets(ctx context.Context, tx *sql.Tx, taxYear int, brackets []TaxBracket) ([]TaxBracket, error) {
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tax_brackets (tax_year, min_total_income, max_total_income, tax_rate)
+		VALUES ($1, $2, $3, $4)
+		RETURNING tax_year, min_total_income, max_total_income, tax_rate
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	inserted := make([]TaxBracket, 0, len(brackets))
+	for _, bracket := range brackets {
+		var b TaxBracket
+		err := stmt.QueryRowContext(ctx, taxYear, bracket.MinTotalIncome, bracket.MaxTotalIncome, bracket.TaxRate).
+			Scan(&b.TaxYear, &b.MinTotalIncome, &b.MaxTotalIncome, &b.TaxRate)
+		if err != nil {
+			return nil, err
+		}
+
+		inserted = append(inserted, b)
+	}
+
+	return inserted, nil
+}
+
+func scanTaxBrackets(rows *sql.Rows) ([]TaxBracket, error) {
+	defer rows.Close()
+
+	var brackets []TaxBracket
+	for rows.Next() {
+		var b TaxBracket
+		err := rows.Scan(&b.TaxYear, &b.MinTotalIncome, &b.MaxTotalIncome, &b.TaxRate)
+		if err != nil {
+			return nil, err
+		}
+
+		brackets = append(brackets, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return brackets, nil
+}
+
+func requireAffectedRows(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -15,11 +15,16 @@ import (
 	"github.com/danyouknowme/assessment-tax/api"
 	"github.com/danyouknowme/assessment-tax/config"
 	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
 
 	_ "github.com/lib/pq"
 )
 
 func main() {
+	// Money is carried as decimal.Decimal; keep it a JSON number so the
+	// wire format matches the original float64 API.
+	decimal.MarshalJSONWithoutQuotes = true
+
 	cfg := config.New()
 
 	conn, err := sql.Open("postgres", cfg.DatabaseUrl)
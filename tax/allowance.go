@@ -0,0 +1,284 @@
+package tax
+
+import (
+	"fmt"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+// AllowanceRule decides how much of the allowances claimed of one type may
+// be deducted. Limit returns the cap the claims were held to, which Allow
+// never exceeds.
+type AllowanceRule interface {
+	Allow(in AllowanceInput) decimal.Decimal
+	Limit(in AllowanceInput) decimal.Decimal
+}
+
+// AllowanceInput is what an AllowanceRule decides on for one allowance type.
+type AllowanceInput struct {
+	Deduction   db.Deduction
+	TotalIncome decimal.Decimal // gross income
+	// NetIncome is the income left after expenses, the personal allowance
+	// and the allowances registered before this type.
+	NetIncome decimal.Decimal
+	Claims    []Allowance
+	// Allowed holds the amounts allowed for the types registered before.
+	Allowed map[string]decimal.Decimal
+}
+
+// Claimed returns the sum of the claimed amounts.
+func (in AllowanceInput) Claimed() decimal.Decimal {
+	claimed := decimal.Zero
+	for _, claim := range in.Claims {
+		claimed = claimed.Add(claim.Amount)
+	}
+
+	return claimed
+}
+
+// Dependants returns the number of dependants claimed.
+func (in AllowanceInput) Dependants() int {
+	count := 0
+	for _, claim := range in.Claims {
+		count += claim.Dependants()
+	}
+
+	return count
+}
+
+// DeductionCap applies the cap rules stored with the deduction. Per-person
+// allowances grant the deduction amount for each dependant claimed, up to
+// MaxCount; others are capped by the deduction amount and the IncomePercent
+// of the total income.
+type DeductionCap struct{}
+
+func (c DeductionCap) Allow(in AllowanceInput) decimal.Decimal {
+	if in.Deduction.PerPerson {
+		return c.Limit(in)
+	}
+
+	return decimal.Min(in.Claimed(), c.Limit(in))
+}
+
+// Limit returns the deduction amount for each dependant claimed up to
+// MaxCount for per-person allowances, otherwise the lower of the deduction
+// amount and the IncomePercent of the total income.
+func (DeductionCap) Limit(in AllowanceInput) decimal.Decimal {
+	if in.Deduction.PerPerson {
+		count := in.Dependants()
+		if in.Deduction.MaxCount > 0 && count > in.Deduction.MaxCount {
+			count = in.Deduction.MaxCount
+		}
+
+		return in.Deduction.Amount.Mul(decimal.NewFromInt(int64(count)))
+	}
+
+	limit := in.Deduction.Amount
+	if in.Deduction.IncomePercent.Valid {
+		limit = decimal.Min(limit, in.TotalIncome.Mul(in.Deduction.IncomePercent.Decimal.Shift(-2)))
+	}
+
+	return limit
+}
+
+// LaterChildCap is DeductionCap for children other than the first, such as
+// the allowance for the second and later children born from 2018. The first
+// child is claimed as the First type, registered before it: until a First
+// child is allowed, the first of the dependants claimed is not.
+type LaterChildCap struct {
+	First string
+}
+
+func (c LaterChildCap) Allow(in AllowanceInput) decimal.Decimal {
+	return c.Limit(in)
+}
+
+// Limit returns the deduction amount for each dependant claimed after the
+// first child, up to MaxCount.
+func (c LaterChildCap) Limit(in AllowanceInput) decimal.Decimal {
+	count := in.Dependants()
+	if count > 0 && !in.Allowed[c.First].IsPositive() {
+		count--
+	}
+	if in.Deduction.MaxCount > 0 && count > in.Deduction.MaxCount {
+		count = in.Deduction.MaxCount
+	}
+
+	return in.Deduction.Amount.Mul(decimal.NewFromInt(int64(count)))
+}
+
+// CombinedCap limits Rule so that the allowance together with the amounts
+// allowed for the With types does not exceed CombinedLimit. The With types
+// must be registered before the type using the cap.
+type CombinedCap struct {
+	Rule          AllowanceRule
+	With          []string
+	CombinedLimit decimal.Decimal
+}
+
+func (c CombinedCap) Allow(in AllowanceInput) decimal.Decimal {
+	return decimal.Min(c.Rule.Allow(in), c.Limit(in))
+}
+
+// Limit returns the lower of the limit of Rule and what is left of the
+// combined cap.
+func (c CombinedCap) Limit(in AllowanceInput) decimal.Decimal {
+	remaining := c.CombinedLimit
+	for _, allowanceType := range c.With {
+		remaining = remaining.Sub(in.Allowed[allowanceType])
+	}
+
+	return decimal.Max(decimal.Min(c.Rule.Limit(in), remaining), decimal.Zero)
+}
+
+// DonationCap allows Multiplier times the donations claimed, up to the
+// deduction amount and the IncomePercent of the income after every other
+// allowance. The donations allowed for the With types share the cap and do
+// not reduce the income it is a percentage of. Donations are registered
+// after every other allowance.
+type DonationCap struct {
+	Multiplier decimal.Decimal
+	With       []string
+}
+
+func (c DonationCap) Allow(in AllowanceInput) decimal.Decimal {
+	return decimal.Min(in.Claimed().Mul(c.Multiplier), c.Limit(in))
+}
+
+// Limit returns what is left of the shared cap after the With types.
+func (c DonationCap) Limit(in AllowanceInput) decimal.Decimal {
+	limit := c.SharedLimit(in)
+	for _, allowanceType := range c.With {
+		limit = limit.Sub(in.Allowed[allowanceType])
+	}
+
+	return decimal.Max(limit, decimal.Zero)
+}
+
+// SharedLimit returns the cap shared by the donation type and the With
+// types.
+func (c DonationCap) SharedLimit(in AllowanceInput) decimal.Decimal {
+	limit := in.Deduction.Amount
+	if in.Deduction.IncomePercent.Valid {
+		income := in.NetIncome
+		for _, allowanceType := range c.With {
+			income = income.Add(in.Allowed[allowanceType])
+		}
+
+		income = decimal.Max(income, decimal.Zero)
+		limit = decimal.Min(limit, income.Mul(in.Deduction.IncomePercent.Decimal.Shift(-2)))
+	}
+
+	return limit
+}
+
+var (
+	allowanceTypes []string
+	allowanceRules = map[string]AllowanceRule{}
+)
+
+// RegisterAllowance makes allowanceType claimable and calculated by rule.
+// Allowances are calculated in registration order. It panics when the type
+// is registered twice.
+func RegisterAllowance(allowanceType string, rule AllowanceRule) {
+	if _, ok := allowanceRules[allowanceType]; ok {
+		panic(fmt.Sprintf("tax: allowance %q registered twice", allowanceType))
+	}
+
+	allowanceTypes = append(allowanceTypes, allowanceType)
+	allowanceRules[allowanceType] = rule
+}
+
+func init() {
+	RegisterAllowance("k-receipt", DeductionCap{})
+	RegisterAllowance("spouse", DeductionCap{})
+	RegisterAllowance("child", DeductionCap{})
+	RegisterAllowance("child-2018", LaterChildCap{First: "child"})
+	RegisterAllowance("parent", DeductionCap{})
+	RegisterAllowance("disabled", DeductionCap{})
+	RegisterAllowance("prenatal", DeductionCap{})
+	RegisterAllowance("life-insurance", DeductionCap{})
+	RegisterAllowance("health-insurance", CombinedCap{
+		Rule:          DeductionCap{},
+		With:          []string{"life-insurance"},
+		CombinedLimit: decimal.NewFromInt(100000),
+	})
+	RegisterAllowance("parent-health-insurance", DeductionCap{})
+	RegisterAllowance("social-security", DeductionCap{})
+	RegisterAllowance("provident-fund", DeductionCap{})
+	RegisterAllowance("ssf", CombinedCap{
+		Rule:          DeductionCap{},
+		With:          []string{"provident-fund"},
+		CombinedLimit: decimal.NewFromInt(500000),
+	})
+	RegisterAllowance("rmf", CombinedCap{
+		Rule:          DeductionCap{},
+		With:          []string{"provident-fund", "ssf"},
+		CombinedLimit: decimal.NewFromInt(500000),
+	})
+	RegisterAllowance("thai-esg", DeductionCap{})
+	RegisterAllowance("home-loan-interest", DeductionCap{})
+	RegisterAllowance("e-receipt", DeductionCap{})
+	RegisterAllowance("donation-education", DonationCap{
+		Multiplier: decimal.NewFromInt(2),
+	})
+	RegisterAllowance("donation", DonationCap{
+		Multiplier: decimal.NewFromInt(1),
+		With:       []string{"donation-education"},
+	})
+}
+
+// AllowanceTypes returns the allowance types a CalculationRequest may claim
+// in registration order. The personal allowance is applied to everyone and
+// is not claimed.
+func AllowanceTypes() []string {
+	return append([]string(nil), allowanceTypes...)
+}
+
+// IsAllowanceType reports whether allowanceType may be claimed.
+func IsAllowanceType(allowanceType string) bool {
+	_, ok := allowanceRules[allowanceType]
+	return ok
+}
+
+// assessAllowances runs every registered rule over the claims of its type
+// in registration order. netIncome is the income after expenses and the
+// personal allowance. It returns the allowed amount and the input each rule
+// decided on by allowance type.
+func assessAllowances(defaultDeductions []db.Deduction, req CalculationRequest, netIncome decimal.Decimal) (map[string]decimal.Decimal, map[string]AllowanceInput) {
+	allowed := make(map[string]decimal.Decimal, len(allowanceTypes))
+	inputs := make(map[string]AllowanceInput, len(allowanceTypes))
+	for _, allowanceType := range allowanceTypes {
+		var claims []Allowance
+		for _, allowance := range req.Allowances {
+			if allowance.AllowanceType == allowanceType {
+				claims = append(claims, allowance)
+			}
+		}
+
+		in := AllowanceInput{
+			Deduction:   getDeductionByType(defaultDeductions, allowanceType),
+			TotalIncome: req.GrossIncome(),
+			NetIncome:   netIncome,
+			Claims:      claims,
+			Allowed:     allowed,
+		}
+		inputs[allowanceType] = in
+		allowed[allowanceType] = allowanceRules[allowanceType].Allow(in)
+		netIncome = netIncome.Sub(allowed[allowanceType])
+	}
+
+	return allowed, inputs
+}
+
+// donationCap returns the cap applied to donations, including education
+// donations, when any donation was claimed.
+func donationCap(inputs map[string]AllowanceInput) decimal.NullDecimal {
+	in := inputs["donation"]
+	if len(in.Claims) == 0 && len(inputs["donation-education"].Claims) == 0 {
+		return decimal.NullDecimal{}
+	}
+
+	return decimal.NewNullDecimal(roundMoney(allowanceRules["donation"].(DonationCap).SharedLimit(in)))
+}
@@ -0,0 +1,237 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateAllowancesCombinedCaps(t *testing.T) {
+	deductions := []db.Deduction{
+		{Type: "life-insurance", Amount: decimal.NewFromInt(100000)},
+		{Type: "health-insurance", Amount: decimal.NewFromInt(25000)},
+		{Type: "provident-fund", Amount: decimal.NewFromInt(500000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(15))},
+		{Type: "ssf", Amount: decimal.NewFromInt(200000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(30))},
+		{Type: "rmf", Amount: decimal.NewFromInt(500000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(30))},
+	}
+
+	testCases := []struct {
+		name       string
+		income     decimal.Decimal
+		allowances []Allowance
+		expect     map[string]decimal.Decimal
+	}{
+		{
+			name:   "Life and health insurance should share 100,000",
+			income: decimal.NewFromInt(1000000),
+			allowances: []Allowance{
+				{AllowanceType: "life-insurance", Amount: decimal.NewFromInt(90000)},
+				{AllowanceType: "health-insurance", Amount: decimal.NewFromInt(25000)},
+			},
+			expect: map[string]decimal.Decimal{
+				"life-insurance":   decimal.NewFromInt(90000),
+				"health-insurance": decimal.NewFromInt(10000),
+			},
+		},
+		{
+			name:   "Provident fund, SSF and RMF should share 500,000",
+			income: decimal.NewFromInt(3000000),
+			allowances: []Allowance{
+				{AllowanceType: "provident-fund", Amount: decimal.NewFromInt(200000)},
+				{AllowanceType: "ssf", Amount: decimal.NewFromInt(200000)},
+				{AllowanceType: "rmf", Amount: decimal.NewFromInt(300000)},
+			},
+			expect: map[string]decimal.Decimal{
+				"provident-fund": decimal.NewFromInt(200000),
+				"ssf":            decimal.NewFromInt(200000),
+				"rmf":            decimal.NewFromInt(100000),
+			},
+		},
+		{
+			name:   "Income percentage should cap before the combined cap",
+			income: decimal.NewFromInt(500000),
+			allowances: []Allowance{
+				{AllowanceType: "ssf", Amount: decimal.NewFromInt(200000)},
+				{AllowanceType: "rmf", Amount: decimal.NewFromInt(200000)},
+			},
+			expect: map[string]decimal.Decimal{
+				"ssf": decimal.NewFromInt(150000),
+				"rmf": decimal.NewFromInt(150000),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed := allowedAmounts(deductions, CalculationRequest{TotalIncome: tc.income, Allowances: tc.allowances})
+
+			for allowanceType, expect := range tc.expect {
+				if !allowed[allowanceType].Equal(expect) {
+					t.Errorf("Expected %v %v, got %v", allowanceType, expect, allowed[allowanceType])
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateDonationCap(t *testing.T) {
+	deductions := []db.Deduction{
+		{Type: "personal", Amount: decimal.NewFromInt(60000)},
+		{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+		{Type: "donation", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+		{Type: "donation-education", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+	}
+
+	testCases := []struct {
+		name          string
+		input         CalculationRequest
+		expectCap     decimal.Decimal
+		expectClaimed bool
+		expectAllowed map[string]decimal.Decimal
+	}{
+		{
+			name: "Donation should be capped at 10% of income after other allowances",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				Allowances: []Allowance{
+					{AllowanceType: "k-receipt", Amount: decimal.NewFromInt(200000)},
+					{AllowanceType: "donation", Amount: decimal.NewFromInt(100000)},
+				},
+			},
+			expectCap:     decimal.NewFromInt(39000),
+			expectClaimed: true,
+			expectAllowed: map[string]decimal.Decimal{"donation": decimal.NewFromInt(39000)},
+		},
+		{
+			name: "Education donation should count double and share the cap",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(1060000),
+				Allowances: []Allowance{
+					{AllowanceType: "donation-education", Amount: decimal.NewFromInt(30000)},
+					{AllowanceType: "donation", Amount: decimal.NewFromInt(80000)},
+				},
+			},
+			expectCap:     decimal.NewFromInt(100000),
+			expectClaimed: true,
+			expectAllowed: map[string]decimal.Decimal{
+				"donation-education": decimal.NewFromInt(60000),
+				"donation":           decimal.NewFromInt(40000),
+			},
+		},
+		{
+			name: "No donation claimed",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+			},
+			expectCap:     decimal.NewFromInt(44000),
+			expectAllowed: map[string]decimal.Decimal{"donation": decimal.Zero},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			donationCap := Calculate(defaultTaxBrackets, deductions, tc.input).DonationCap
+			if donationCap.Valid != tc.expectClaimed {
+				t.Fatalf("Expected claimed %v, got %v", tc.expectClaimed, donationCap.Valid)
+			}
+			if donationCap.Valid && !donationCap.Decimal.Equal(tc.expectCap) {
+				t.Errorf("Expected cap %v, got %v", tc.expectCap, donationCap.Decimal)
+			}
+
+			allowed := allowedAmounts(deductions, tc.input)
+			for allowanceType, expect := range tc.expectAllowed {
+				if !allowed[allowanceType].Equal(expect) {
+					t.Errorf("Expected %v %v, got %v", allowanceType, expect, allowed[allowanceType])
+				}
+			}
+		})
+	}
+}
+
+// allowedAmounts returns the amount allowed by claimed allowance type.
+func allowedAmounts(deductions []db.Deduction, req CalculationRequest) map[string]decimal.Decimal {
+	allowed := map[string]decimal.Decimal{}
+	for _, step := range Calculate(defaultTaxBrackets, deductions, req).Trace.Allowances {
+		allowed[step.AllowanceType] = step.Allowed
+	}
+
+	return allowed
+}
+
+func TestCalculateLaterChildAllowance(t *testing.T) {
+	deductions := []db.Deduction{
+		{Type: "child", Amount: decimal.NewFromInt(30000), PerPerson: true},
+		{Type: "child-2018", Amount: decimal.NewFromInt(60000), PerPerson: true},
+	}
+
+	testCases := []struct {
+		name        string
+		allowances  []Allowance
+		expectChild decimal.Decimal
+		expectLater decimal.Decimal
+	}{
+		{
+			name: "Children born from 2018 after a first child should all be allowed",
+			allowances: []Allowance{
+				{AllowanceType: "child", Count: 1},
+				{AllowanceType: "child-2018", Count: 2},
+			},
+			expectChild: decimal.NewFromInt(30000),
+			expectLater: decimal.NewFromInt(120000),
+		},
+		{
+			name: "A lone child born from 2018 is the first child and should not be allowed",
+			allowances: []Allowance{
+				{AllowanceType: "child-2018", Count: 1},
+			},
+			expectChild: decimal.Zero,
+			expectLater: decimal.Zero,
+		},
+		{
+			name: "Children born from 2018 without a first child should allow all but the first",
+			allowances: []Allowance{
+				{AllowanceType: "child-2018", Count: 3},
+			},
+			expectChild: decimal.Zero,
+			expectLater: decimal.NewFromInt(120000),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed := allowedAmounts(deductions, CalculationRequest{TotalIncome: decimal.NewFromInt(1000000), Allowances: tc.allowances})
+
+			if !allowed["child"].Equal(tc.expectChild) {
+				t.Errorf("Expected child %v, got %v", tc.expectChild, allowed["child"])
+			}
+			if !allowed["child-2018"].Equal(tc.expectLater) {
+				t.Errorf("Expected child-2018 %v, got %v", tc.expectLater, allowed["child-2018"])
+			}
+		})
+	}
+}
+
+func TestIsAllowanceType(t *testing.T) {
+	for _, allowanceType := range AllowanceTypes() {
+		if !IsAllowanceType(allowanceType) {
+			t.Errorf("Expected %v to be an allowance type", allowanceType)
+		}
+	}
+
+	for _, allowanceType := range []string{"personal", "invalid", ""} {
+		if IsAllowanceType(allowanceType) {
+			t.Errorf("Expected %v not to be an allowance type", allowanceType)
+		}
+	}
+}
+
+func TestRegisterAllowanceTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+
+	RegisterAllowance("donation", DeductionCap{})
+}
@@ -0,0 +1,116 @@
+package tax
+
+import "github.com/shopspring/decimal"
+
+// DividendOption selects how Thai dividends are taxed.
+type DividendOption string
+
+const (
+	// DividendCredit includes the dividends, grossed up by the corporate
+	// tax credit, in the income and credits the tax withheld on them and
+	// the corporate tax credit against the tax.
+	DividendCredit DividendOption = "credit"
+	// DividendFinal elects the 10% withheld as final: the dividends are left
+	// out of the income and the tax withheld on them is not credited.
+	DividendFinal DividendOption = "final"
+)
+
+// Dividend is a Thai dividend, 40(4)(b) income, paid out of profits taxed
+// at CorporateRate percent. Wht is the tax withheld on it, normally 10%.
+// A CorporateRate of zero grants no dividend credit.
+type Dividend struct {
+	Amount        decimal.Decimal `json:"amount" validate:"gt=0"`
+	Wht           decimal.Decimal `json:"wht" validate:"credit_custom_validation"`
+	CorporateRate decimal.Decimal `json:"corporateRate" validate:"min=0,lt=100"`
+}
+
+// Credit returns the corporate tax credit of the dividend,
+// Amount × CorporateRate / (100 − CorporateRate).
+func (d Dividend) Credit() decimal.Decimal {
+	return roundMoney(d.Amount.Mul(d.CorporateRate).Div(decimal.NewFromInt(100).Sub(d.CorporateRate)))
+}
+
+// ForeignIncome is an income earned abroad on which ForeignTax was paid.
+// Its expenses are deducted as for an income of the same category at home.
+type ForeignIncome struct {
+	Income
+	Country    string          `json:"country,omitempty"`
+	ForeignTax decimal.Decimal `json:"foreignTax" validate:"credit_custom_validation"`
+}
+
+// Credits are what is credited against the tax: the ordinary WHT, the tax
+// withheld on dividends, the corporate tax credit of dividends and the tax
+// paid abroad up to the Thai tax on the foreign income.
+type Credits struct {
+	Wht              decimal.Decimal `json:"wht"`
+	DividendWht      decimal.Decimal `json:"dividendWht"`
+	DividendCredit   decimal.Decimal `json:"dividendCredit"`
+	ForeignTaxCredit decimal.Decimal `json:"foreignTaxCredit"`
+	Total            decimal.Decimal `json:"total"`
+}
+
+// hasCredits reports whether req has more to credit than its WHT.
+func (r CalculationRequest) hasCredits() bool {
+	return len(r.Dividends) > 0 || len(r.ForeignIncomes) > 0
+}
+
+// DividendTaxOption returns the dividend option, defaulting to
+// DividendCredit.
+func (r CalculationRequest) DividendTaxOption() DividendOption {
+	if r.DividendOption == "" {
+		return DividendCredit
+	}
+
+	return r.DividendOption
+}
+
+// dividendIncome returns the dividends included in the income together
+// with their corporate tax credit, none when final withholding is elected.
+func (r CalculationRequest) dividendIncome() decimal.Decimal {
+	income := decimal.Zero
+	if r.DividendTaxOption() == DividendFinal {
+		return income
+	}
+
+	for _, dividend := range r.Dividends {
+		income = income.Add(dividend.Amount).Add(dividend.Credit())
+	}
+
+	return income
+}
+
+// calculateCredits works out the credits against tax. expenses are the
+// expenses of the incomes of req followed by those of its foreign incomes,
+// and netIncome the income after all of them. The tax paid on a foreign
+// income is credited up to the part of tax its income after expenses is of
+// netIncome.
+func calculateCredits(req CalculationRequest, tax decimal.Decimal, expenses []IncomeExpense, netIncome decimal.Decimal) Credits {
+	credits := Credits{
+		Wht:              req.Wht,
+		DividendWht:      decimal.Zero,
+		DividendCredit:   decimal.Zero,
+		ForeignTaxCredit: decimal.Zero,
+	}
+
+	if req.DividendTaxOption() == DividendCredit {
+		for _, dividend := range req.Dividends {
+			credits.DividendWht = credits.DividendWht.Add(dividend.Wht)
+			credits.DividendCredit = credits.DividendCredit.Add(dividend.Credit())
+		}
+	}
+
+	foreignExpenses := expenses[len(expenses)-len(req.ForeignIncomes):]
+	for i, income := range req.ForeignIncomes {
+		attributable := decimal.Zero
+		if netIncome.IsPositive() {
+			foreignNetIncome := income.Amount.Sub(foreignExpenses[i].Expense)
+			attributable = roundMoney(tax.Mul(foreignNetIncome).Div(netIncome))
+		}
+
+		credits.ForeignTaxCredit = credits.ForeignTaxCredit.Add(decimal.Min(income.ForeignTax, attributable))
+	}
+
+	credits.Total = credits.Wht.Add(credits.DividendWht).Add(credits.DividendCredit).Add(credits.ForeignTaxCredit)
+
+	return credits
+}
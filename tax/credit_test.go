@@ -0,0 +1,115 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateCredits(t *testing.T) {
+	dividends := []Dividend{
+		{Amount: decimal.NewFromInt(100000), Wht: decimal.NewFromInt(10000), CorporateRate: decimal.NewFromInt(20)},
+	}
+
+	testCases := []struct {
+		name          string
+		input         CalculationRequest
+		expectTax     decimal.Decimal
+		expectCredits Credits
+	}{
+		{
+			name: "Dividends should be grossed up and credited",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				Dividends:   dividends,
+			},
+			expectTax: decimal.NewFromInt(9750),
+			expectCredits: Credits{
+				Wht:              decimal.Zero,
+				DividendWht:      decimal.NewFromInt(10000),
+				DividendCredit:   decimal.NewFromInt(25000),
+				ForeignTaxCredit: decimal.Zero,
+				Total:            decimal.NewFromInt(35000),
+			},
+		},
+		{
+			name: "Dividends under final withholding should be left out",
+			input: CalculationRequest{
+				TotalIncome:    decimal.NewFromInt(500000),
+				Wht:            decimal.NewFromInt(20000),
+				Dividends:      dividends,
+				DividendOption: DividendFinal,
+			},
+			expectTax: decimal.NewFromInt(9000),
+			expectCredits: Credits{
+				Wht:              decimal.NewFromInt(20000),
+				DividendWht:      decimal.Zero,
+				DividendCredit:   decimal.Zero,
+				ForeignTaxCredit: decimal.Zero,
+				Total:            decimal.NewFromInt(20000),
+			},
+		},
+		{
+			name: "Foreign tax should be capped at the Thai tax on the foreign income",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				ForeignIncomes: []ForeignIncome{
+					{Income: Income{Category: "40(8)", Amount: decimal.NewFromInt(200000)}, ForeignTax: decimal.NewFromInt(30000)},
+				},
+			},
+			expectTax: decimal.RequireFromString("32758.62"),
+			expectCredits: Credits{
+				Wht:              decimal.Zero,
+				DividendWht:      decimal.Zero,
+				DividendCredit:   decimal.Zero,
+				ForeignTaxCredit: decimal.RequireFromString("5241.38"),
+				Total:            decimal.RequireFromString("5241.38"),
+			},
+		},
+		{
+			name: "Foreign tax below the cap should be credited in full",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				ForeignIncomes: []ForeignIncome{
+					{Income: Income{Category: "40(8)", Amount: decimal.NewFromInt(200000)}, ForeignTax: decimal.NewFromInt(1000)},
+				},
+			},
+			expectTax: decimal.NewFromInt(37000),
+			expectCredits: Credits{
+				Wht:              decimal.Zero,
+				DividendWht:      decimal.Zero,
+				DividendCredit:   decimal.Zero,
+				ForeignTaxCredit: decimal.NewFromInt(1000),
+				Total:            decimal.NewFromInt(1000),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+
+			if !res.Tax.Equal(tc.expectTax) {
+				t.Errorf("Expected tax %v, got %v", tc.expectTax, res.Tax)
+			}
+
+			if res.Credits == nil {
+				t.Fatal("Expected credits")
+			}
+
+			credits := *res.Credits
+			if !credits.Wht.Equal(tc.expectCredits.Wht) || !credits.DividendWht.Equal(tc.expectCredits.DividendWht) ||
+				!credits.DividendCredit.Equal(tc.expectCredits.DividendCredit) || !credits.ForeignTaxCredit.Equal(tc.expectCredits.ForeignTaxCredit) ||
+				!credits.Total.Equal(tc.expectCredits.Total) {
+				t.Errorf("Expected credits %+v, got %+v", tc.expectCredits, credits)
+			}
+		})
+	}
+}
+
+func TestCalculateWithoutCredits(t *testing.T) {
+	res := Calculate(defaultTaxBrackets, defaultDeductions, CalculationRequest{TotalIncome: decimal.NewFromInt(500000)})
+	if res.Credits != nil {
+		t.Errorf("Expected no credits, got %+v", res.Credits)
+	}
+}
@@ -0,0 +1,53 @@
+package tax
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Date is a calendar date carried as "2006-01-02" in JSON. The zero Date
+// means the date was not given.
+type Date struct {
+	time.Time
+}
+
+// NewDate truncates t to its calendar date.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func Today() Date {
+	return NewDate(time.Now())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if value == "" {
+		*d = Date{}
+		return nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return err
+	}
+
+	*d = Date{t}
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+func (d Date) String() string {
+	return d.Format(time.DateOnly)
+}
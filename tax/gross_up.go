@@ -0,0 +1,148 @@
+package tax
+
+import (
+	"errors"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+// ErrGrossUpUnsolvable is returned when no gross income yields the net
+// income asked for, e.g. when a bracket taxes every further baht.
+var ErrGrossUpUnsolvable = errors.New("no gross income yields the net income")
+
+// maxGrossUpRounds bounds the rounds spent finding how the allowances
+// grow with the gross income; each settles at least one income cap.
+const maxGrossUpRounds = 16
+
+var satang = decimal.New(1, -2)
+
+// GrossUpRequest asks for the gross income whose net income after tax is
+// NetIncome, under the same allowances, WHT and settings as a
+// CalculationRequest. WHT only changes how the tax is settled, not the net
+// income.
+type GrossUpRequest struct {
+	NetIncome  decimal.Decimal `json:"netIncome" validate:"required,gt=0"`
+	Wht        decimal.Decimal `json:"wht" validate:"min=0.0"`
+	Allowances []Allowance     `json:"allowances" validate:"dive"`
+	Rounding   Rounding        `json:"rounding,omitempty" validate:"omitempty,oneof=total bracket"`
+	TaxYear    int             `json:"taxYear,omitempty" validate:"omitempty,min=1900,max=2100"`
+	AsOf       Date            `json:"asOf"`
+}
+
+// CalculationRequest returns the calculation of grossIncome under r.
+func (r GrossUpRequest) CalculationRequest(grossIncome decimal.Decimal) CalculationRequest {
+	return CalculationRequest{
+		TotalIncome: grossIncome,
+		Wht:         r.Wht,
+		Allowances:  r.Allowances,
+		Rounding:    r.Rounding,
+		TaxYear:     r.TaxYear,
+		AsOf:        r.AsOf,
+	}
+}
+
+// GrossUp returns the lowest gross income, to the satang, whose net income
+// reaches req.NetIncome, together with its calculation.
+//
+// The tax is inverted bracket by bracket. The allowances are taken as
+// growing linearly with the gross income, a line redrawn through the last
+// two gross incomes tried until it agrees with the allowances of the gross
+// income solved for, which happens once the income caps in play are known.
+func GrossUp(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, req GrossUpRequest) (decimal.Decimal, Result, error) {
+	prevGross := req.NetIncome
+	prevAllowances := grossUpAllowances(defaultDeductions, req, prevGross)
+	slope := decimal.Zero
+
+	var grossIncome decimal.Decimal
+	for round := 0; ; round++ {
+		if round == maxGrossUpRounds {
+			return decimal.Zero, Result{}, ErrGrossUpUnsolvable
+		}
+
+		// allowances(g) = base + slope × g
+		base := prevAllowances.Sub(slope.Mul(prevGross))
+		gross, ok := invertBrackets(taxBrackets, req.NetIncome, base, slope)
+		if !ok {
+			return decimal.Zero, Result{}, ErrGrossUpUnsolvable
+		}
+
+		allowances := grossUpAllowances(defaultDeductions, req, gross)
+		if allowances.Sub(base.Add(slope.Mul(gross))).Abs().LessThan(satang) {
+			grossIncome = gross
+			break
+		}
+
+		slope = allowances.Sub(prevAllowances).Div(gross.Sub(prevGross))
+		prevGross, prevAllowances = gross, allowances
+	}
+
+	// The allowances and the tax are rounded to the satang, which may move
+	// the answer a satang either way of the exact one.
+	grossIncome = grossIncome.RoundCeil(2)
+	res := Calculate(taxBrackets, defaultDeductions, req.CalculationRequest(grossIncome))
+	if res.NetIncome.LessThan(req.NetIncome) {
+		grossIncome = grossIncome.Add(satang)
+		return grossIncome, Calculate(taxBrackets, defaultDeductions, req.CalculationRequest(grossIncome)), nil
+	}
+
+	if lower := grossIncome.Sub(satang); lower.GreaterThanOrEqual(req.NetIncome) {
+		if below := Calculate(taxBrackets, defaultDeductions, req.CalculationRequest(lower)); !below.NetIncome.LessThan(req.NetIncome) {
+			return lower, below, nil
+		}
+	}
+
+	return grossIncome, res, nil
+}
+
+// grossUpAllowances returns the allowances of grossIncome under req.
+func grossUpAllowances(defaultDeductions []db.Deduction, req GrossUpRequest, grossIncome decimal.Decimal) decimal.Decimal {
+	personal := getDeductionByType(defaultDeductions, "personal").Amount
+	allowed, _ := assessAllowances(defaultDeductions, req.CalculationRequest(grossIncome), grossIncome.Sub(personal))
+
+	total := personal
+	for _, amount := range allowed {
+		total = total.Add(amount)
+	}
+
+	return roundMoney(total)
+}
+
+// invertBrackets solves net = g − tax(g − allowances) for the gross income
+// g, the allowances being base + slope × g. Within a bracket starting at
+// taxable income start, after tax taxed on the brackets below it, at rate r:
+//
+//	g = (net + taxed − r × (start + base)) / (1 − r × (1 − slope))
+//
+// It reports false when no bracket holds the solution.
+func invertBrackets(taxBrackets []db.TaxBracket, net, base, slope decimal.Decimal) (decimal.Decimal, bool) {
+	one := decimal.NewFromInt(1)
+	start, taxed := decimal.Zero, decimal.Zero
+	for i, bracket := range taxBrackets {
+		rate := bracket.TaxRate.Shift(-2)
+		width := decimal.Zero
+		if bracket.MaxTotalIncome.Valid {
+			width = bracket.MaxTotalIncome.Decimal.Sub(bracket.MinTotalIncome)
+		}
+
+		denominator := one.Sub(rate.Mul(one.Sub(slope)))
+		if denominator.IsPositive() {
+			gross := net.Add(taxed).Sub(rate.Mul(start.Add(base))).Div(denominator)
+			taxable := gross.Sub(base.Add(slope.Mul(gross)))
+
+			inBracket := (i == 0 || !taxable.LessThan(start)) &&
+				(!bracket.MaxTotalIncome.Valid || !taxable.GreaterThan(start.Add(width)))
+			if inBracket {
+				return gross, true
+			}
+		}
+
+		if !bracket.MaxTotalIncome.Valid {
+			break
+		}
+		start = start.Add(width)
+		taxed = taxed.Add(rate.Mul(width))
+	}
+
+	return decimal.Zero, false
+}
@@ -0,0 +1,112 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestGrossUp(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       GrossUpRequest
+		deductions  []db.Deduction
+		expectGross decimal.Decimal
+	}{
+		{
+			name:        "Net income below the tax-free threshold should need no gross-up",
+			input:       GrossUpRequest{NetIncome: decimal.NewFromInt(200000)},
+			deductions:  defaultDeductions,
+			expectGross: decimal.NewFromInt(200000),
+		},
+		{
+			name:        "Net income 471,000 should gross up to 500,000",
+			input:       GrossUpRequest{NetIncome: decimal.NewFromInt(471000)},
+			deductions:  defaultDeductions,
+			expectGross: decimal.NewFromInt(500000),
+		},
+		{
+			name:        "Net income across several brackets should gross up exactly",
+			input:       GrossUpRequest{NetIncome: decimal.NewFromInt(1500000)},
+			deductions:  defaultDeductions,
+			expectGross: decimal.RequireFromString("1747500"),
+		},
+		{
+			name: "Allowances should lower the gross income needed",
+			input: GrossUpRequest{
+				NetIncome: decimal.NewFromInt(481000),
+				Allowances: []Allowance{
+					{AllowanceType: "donation", Amount: decimal.NewFromInt(100000)},
+				},
+			},
+			deductions:  defaultDeductions,
+			expectGross: decimal.NewFromInt(500000),
+		},
+		{
+			name:        "Net income on a bracket boundary should gross up to it",
+			input:       GrossUpRequest{NetIncome: decimal.NewFromInt(210000)},
+			deductions:  defaultDeductions,
+			expectGross: decimal.NewFromInt(210000),
+		},
+		{
+			name: "Donations capped by the income should be honoured",
+			input: GrossUpRequest{
+				NetIncome: decimal.NewFromInt(300000),
+				Allowances: []Allowance{
+					{AllowanceType: "donation", Amount: decimal.NewFromInt(100000)},
+				},
+			},
+			deductions: []db.Deduction{
+				{Type: "personal", Amount: decimal.NewFromInt(60000)},
+				{Type: "donation", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+			},
+			expectGross: decimal.RequireFromString("307252.75"),
+		},
+		{
+			name: "Income percentage caps should be honoured",
+			input: GrossUpRequest{
+				NetIncome: decimal.NewFromInt(400000),
+				Allowances: []Allowance{
+					{AllowanceType: "ssf", Amount: decimal.NewFromInt(200000)},
+				},
+			},
+			deductions:  allowanceDeductions,
+			expectGross: decimal.RequireFromString("407526.88"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gross, res, err := GrossUp(defaultTaxBrackets, tc.deductions, tc.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !gross.Equal(tc.expectGross) {
+				t.Errorf("Expected gross %v, got %v", tc.expectGross, gross)
+			}
+
+			if res.NetIncome.LessThan(tc.input.NetIncome) {
+				t.Errorf("Expected net income of at least %v, got %v", tc.input.NetIncome, res.NetIncome)
+			}
+
+			below := Calculate(defaultTaxBrackets, tc.deductions, tc.input.CalculationRequest(gross.Sub(satang)))
+			if !below.NetIncome.LessThan(tc.input.NetIncome) {
+				t.Errorf("Expected %v to be the lowest gross income, %v also reaches %v", gross, gross.Sub(satang), below.NetIncome)
+			}
+		})
+	}
+}
+
+func TestGrossUpUnsolvable(t *testing.T) {
+	taxBrackets := []db.TaxBracket{
+		{MinTotalIncome: decimal.Zero, MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(100000)), TaxRate: decimal.Zero},
+		{MinTotalIncome: decimal.NewFromInt(100000), TaxRate: decimal.NewFromInt(100)},
+	}
+
+	_, _, err := GrossUp(taxBrackets, defaultDeductions, GrossUpRequest{NetIncome: decimal.NewFromInt(500000)})
+	if err != ErrGrossUpUnsolvable {
+		t.Errorf("Expected ErrGrossUpUnsolvable, got %v", err)
+	}
+}
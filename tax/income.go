@@ -0,0 +1,120 @@
+package tax
+
+import "github.com/shopspring/decimal"
+
+// ExpenseMethod selects how the expenses of an income are deducted.
+type ExpenseMethod string
+
+const (
+	// ExpenseStandard deducts the statutory percentage of the income.
+	ExpenseStandard ExpenseMethod = "standard"
+	// ExpenseActual deducts the actual expenses, up to the income.
+	ExpenseActual ExpenseMethod = "actual"
+)
+
+// Income is an assessable income of one section 40 category, e.g. "40(1)"
+// for salary or "40(8)" for business income.
+type Income struct {
+	Category       string          `json:"category" validate:"income_category_custom_validation"`
+	Amount         decimal.Decimal `json:"amount" validate:"min=0.0"`
+	ExpenseMethod  ExpenseMethod   `json:"expenseMethod,omitempty" validate:"omitempty,oneof=standard actual,expense_method_custom_validation"`
+	ActualExpenses decimal.Decimal `json:"actualExpenses" validate:"min=0.0"`
+
+	// spouse marks an income of the spouse on a joint return, which uses up
+	// expense limits of its own.
+	spouse bool
+}
+
+// Method returns the expense method, defaulting to ExpenseStandard.
+func (i Income) Method() ExpenseMethod {
+	if i.ExpenseMethod == "" {
+		return ExpenseStandard
+	}
+
+	return i.ExpenseMethod
+}
+
+// expenseRule is the statutory expense deduction of an income category:
+// Percent of the income, capped at Limit shared by every category of the
+// same Group, or the actual expenses when AllowActual is set.
+type expenseRule struct {
+	Percent     decimal.Decimal
+	Group       string
+	Limit       decimal.NullDecimal
+	AllowActual bool
+}
+
+var expenseRules = map[string]expenseRule{
+	"40(1)": {Percent: decimal.NewFromInt(50), Group: "40(1-2)", Limit: decimal.NewNullDecimal(decimal.NewFromInt(100000))},
+	"40(2)": {Percent: decimal.NewFromInt(50), Group: "40(1-2)", Limit: decimal.NewNullDecimal(decimal.NewFromInt(100000))},
+	"40(3)": {Percent: decimal.NewFromInt(50), Group: "40(3)", Limit: decimal.NewNullDecimal(decimal.NewFromInt(100000)), AllowActual: true},
+	"40(4)": {Percent: decimal.Zero},
+	"40(5)": {Percent: decimal.NewFromInt(30), AllowActual: true},
+	"40(6)": {Percent: decimal.NewFromInt(30), AllowActual: true},
+	"40(7)": {Percent: decimal.NewFromInt(60), AllowActual: true},
+	"40(8)": {Percent: decimal.NewFromInt(60), AllowActual: true},
+}
+
+// IsIncomeCategory reports whether category is a section 40 income category.
+func IsIncomeCategory(category string) bool {
+	_, ok := expenseRules[category]
+	return ok
+}
+
+// AllowsActualExpenses reports whether the income category may deduct
+// actual expenses instead of the standard percentage.
+func AllowsActualExpenses(category string) bool {
+	return expenseRules[category].AllowActual
+}
+
+// IncomeExpense is the expense deducted from one income.
+type IncomeExpense struct {
+	Category      string          `json:"category"`
+	Amount        decimal.Decimal `json:"amount"`
+	ExpenseMethod ExpenseMethod   `json:"expenseMethod"`
+	Expense       decimal.Decimal `json:"expense"`
+}
+
+// calculateExpenses returns the expense deducted from each income of req in
+// request order, followed by its foreign incomes. Categories sharing a limit
+// use it up in that order, each spouse of a joint return up to a limit of
+// their own.
+func calculateExpenses(req CalculationRequest) []IncomeExpense {
+	var expenses []IncomeExpense
+
+	incomes := append([]Income(nil), req.Incomes...)
+	for _, income := range req.ForeignIncomes {
+		incomes = append(incomes, income.Income)
+	}
+
+	type limitKey struct {
+		group  string
+		spouse bool
+	}
+
+	used := map[limitKey]decimal.Decimal{}
+	for _, income := range incomes {
+		rule := expenseRules[income.Category]
+
+		var expense decimal.Decimal
+		if income.Method() == ExpenseActual && rule.AllowActual {
+			expense = decimal.Min(income.ActualExpenses, income.Amount)
+		} else {
+			expense = income.Amount.Mul(rule.Percent.Shift(-2))
+			if rule.Limit.Valid {
+				key := limitKey{rule.Group, income.spouse}
+				expense = decimal.Min(expense, rule.Limit.Decimal.Sub(used[key]))
+				used[key] = used[key].Add(expense)
+			}
+		}
+
+		expenses = append(expenses, IncomeExpense{
+			Category:      income.Category,
+			Amount:        income.Amount,
+			ExpenseMethod: income.Method(),
+			Expense:       roundMoney(expense),
+		})
+	}
+
+	return expenses
+}
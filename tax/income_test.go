@@ -0,0 +1,74 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateExpenses(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  []Income
+		expect []decimal.Decimal
+	}{
+		{
+			name: "40(1) and 40(2) should share 100,000",
+			input: []Income{
+				{Category: "40(1)", Amount: decimal.NewFromInt(150000)},
+				{Category: "40(2)", Amount: decimal.NewFromInt(200000)},
+			},
+			expect: []decimal.Decimal{decimal.NewFromInt(75000), decimal.NewFromInt(25000)},
+		},
+		{
+			name: "40(5) standard 30% and 40(8) actual expenses up to the income",
+			input: []Income{
+				{Category: "40(5)", Amount: decimal.NewFromInt(100000)},
+				{Category: "40(8)", Amount: decimal.NewFromInt(300000), ExpenseMethod: ExpenseActual, ActualExpenses: decimal.NewFromInt(120000)},
+				{Category: "40(8)", Amount: decimal.NewFromInt(300000), ExpenseMethod: ExpenseActual, ActualExpenses: decimal.NewFromInt(500000)},
+			},
+			expect: []decimal.Decimal{decimal.NewFromInt(30000), decimal.NewFromInt(120000), decimal.NewFromInt(300000)},
+		},
+		{
+			name: "40(4) should have no expenses",
+			input: []Income{
+				{Category: "40(4)", Amount: decimal.NewFromInt(50000)},
+			},
+			expect: []decimal.Decimal{decimal.Zero},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expenses := calculateExpenses(CalculationRequest{Incomes: tc.input})
+
+			if len(expenses) != len(tc.expect) {
+				t.Fatalf("Expected %d expenses, got %d", len(tc.expect), len(expenses))
+			}
+
+			for i, expense := range expenses {
+				if !expense.Expense.Equal(tc.expect[i]) {
+					t.Errorf("Expected expense %v of %v, got %v", tc.expect[i], expense.Category, expense.Expense)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateTaxWithIncomes(t *testing.T) {
+	req := CalculationRequest{
+		Incomes: []Income{
+			{Category: "40(1)", Amount: decimal.NewFromInt(600000)},
+			{Category: "40(8)", Amount: decimal.NewFromInt(300000)},
+		},
+	}
+
+	if gross := req.GrossIncome(); !gross.Equal(decimal.NewFromInt(900000)) {
+		t.Errorf("Expected gross income 900000, got %v", gross)
+	}
+
+	tax := Calculate(defaultTaxBrackets, defaultDeductions, req).Tax
+	if !tax.Equal(decimal.NewFromInt(44000)) {
+		t.Errorf("Expected 44000, got %v", tax)
+	}
+}
@@ -0,0 +1,56 @@
+package tax
+
+import "github.com/shopspring/decimal"
+
+// TaxMethod names how the tax was calculated.
+type TaxMethod string
+
+const (
+	// MethodProgressive applies the tax brackets to the taxable income.
+	MethodProgressive TaxMethod = "progressive"
+	// MethodMinimum charges 0.5% of the income other than 40(1).
+	MethodMinimum TaxMethod = "minimum"
+)
+
+var (
+	minimumTaxRate      = decimal.NewFromInt(5).Shift(-3)
+	minimumTaxThreshold = decimal.NewFromInt(120000)
+	minimumTaxExemption = decimal.NewFromInt(5000)
+)
+
+// TaxMethods compares the progressive tax with the minimum tax and names
+// the method that won.
+type TaxMethods struct {
+	ProgressiveTax decimal.Decimal `json:"progressiveTax"`
+	MinimumTax     decimal.Decimal `json:"minimumTax"`
+	Method         TaxMethod       `json:"method"`
+}
+
+// compareTaxMethods compares both methods, before WHT, when req has income
+// other than 40(1) of 120,000 or more, and reports false when only the
+// progressive method applies. The minimum tax is charged instead of
+// progressiveTax when it is larger; a minimum tax of 5,000 or less is not
+// charged.
+func compareTaxMethods(progressiveTax decimal.Decimal, req CalculationRequest) (TaxMethods, bool) {
+	base := decimal.Zero
+	for _, income := range req.Incomes {
+		if income.Category != "40(1)" {
+			base = base.Add(income.Amount)
+		}
+	}
+
+	if base.LessThan(minimumTaxThreshold) {
+		return TaxMethods{}, false
+	}
+
+	methods := TaxMethods{
+		ProgressiveTax: progressiveTax,
+		MinimumTax:     roundMoney(base.Mul(minimumTaxRate)),
+		Method:         MethodProgressive,
+	}
+	if methods.MinimumTax.GreaterThan(minimumTaxExemption) && methods.MinimumTax.GreaterThan(progressiveTax) {
+		methods.Method = MethodMinimum
+	}
+
+	return methods, true
+}
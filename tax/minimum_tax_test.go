@@ -0,0 +1,97 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateTaxMethods(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          CalculationRequest
+		expectRequired bool
+		expectMethods  TaxMethods
+		expectTax      decimal.Decimal
+	}{
+		{
+			name: "Business income with actual expenses should pay the minimum tax",
+			input: CalculationRequest{
+				Incomes: []Income{
+					{Category: "40(8)", Amount: decimal.NewFromInt(2000000), ExpenseMethod: ExpenseActual, ActualExpenses: decimal.NewFromInt(1900000)},
+				},
+			},
+			expectRequired: true,
+			expectMethods: TaxMethods{
+				ProgressiveTax: decimal.Zero,
+				MinimumTax:     decimal.NewFromInt(10000),
+				Method:         MethodMinimum,
+			},
+			expectTax: decimal.NewFromInt(10000),
+		},
+		{
+			name: "Minimum tax of 5,000 or less should not be charged",
+			input: CalculationRequest{
+				Incomes: []Income{
+					{Category: "40(8)", Amount: decimal.NewFromInt(1000000), ExpenseMethod: ExpenseActual, ActualExpenses: decimal.NewFromInt(1000000)},
+				},
+			},
+			expectRequired: true,
+			expectMethods: TaxMethods{
+				ProgressiveTax: decimal.Zero,
+				MinimumTax:     decimal.NewFromInt(5000),
+				Method:         MethodProgressive,
+			},
+			expectTax: decimal.Zero,
+		},
+		{
+			name: "Progressive tax larger than the minimum tax should win",
+			input: CalculationRequest{
+				Incomes: []Income{
+					{Category: "40(1)", Amount: decimal.NewFromInt(1000000)},
+					{Category: "40(2)", Amount: decimal.NewFromInt(200000)},
+				},
+			},
+			expectRequired: true,
+			expectMethods: TaxMethods{
+				ProgressiveTax: decimal.NewFromInt(118000),
+				MinimumTax:     decimal.NewFromInt(1000),
+				Method:         MethodProgressive,
+			},
+			expectTax: decimal.NewFromInt(118000),
+		},
+		{
+			name: "Salary only should not compare methods",
+			input: CalculationRequest{
+				Incomes: []Income{
+					{Category: "40(1)", Amount: decimal.NewFromInt(5000000)},
+					{Category: "40(8)", Amount: decimal.NewFromInt(100000)},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+			if required := res.TaxMethods != nil; required != tc.expectRequired {
+				t.Fatalf("Expected required %v, got %v", tc.expectRequired, required)
+			}
+			if res.TaxMethods == nil {
+				return
+			}
+
+			methods := *res.TaxMethods
+
+			if !methods.ProgressiveTax.Equal(tc.expectMethods.ProgressiveTax) ||
+				!methods.MinimumTax.Equal(tc.expectMethods.MinimumTax) ||
+				methods.Method != tc.expectMethods.Method {
+				t.Errorf("Expected %+v, got %+v", tc.expectMethods, methods)
+			}
+
+			if tax := res.Tax; !tax.Equal(tc.expectTax) {
+				t.Errorf("Expected tax %v, got %v", tc.expectTax, tax)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package tax
+
+import "github.com/shopspring/decimal"
+
+// Rounding selects when calculated tax is rounded to satang. Both modes
+// round half-up to two decimal places.
+type Rounding string
+
+const (
+	// RoundingTotal sums the exact tax of every bracket and rounds once.
+	RoundingTotal Rounding = "total"
+	// RoundingBracket rounds the tax of each bracket before summing, so the
+	// total always equals the sum of the reported tax levels.
+	RoundingBracket Rounding = "bracket"
+)
+
+func roundMoney(amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(2)
+}
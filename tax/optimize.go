@@ -0,0 +1,242 @@
+package tax
+
+import (
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+// OptimizableAllowanceTypes are the allowances bought with cash that the
+// optimizer allocates a budget to unless told otherwise.
+var OptimizableAllowanceTypes = []string{
+	"k-receipt",
+	"life-insurance",
+	"health-insurance",
+	"ssf",
+	"rmf",
+	"donation-education",
+	"donation",
+}
+
+var (
+	// optimizeStep bounds each allocation so that the optimizer follows the
+	// marginal rate down instead of averaging it over a whole cap.
+	optimizeStep = decimal.NewFromInt(10000)
+	// maxOptimizeRounds bounds how often the allocation is trimmed of claims
+	// a later allocation made useless and allocated again.
+	maxOptimizeRounds = 8
+)
+
+// OptimizeRequest asks how to spend Budget on top of the allowances of Base
+// to cut the most tax, considering AllowanceTypes or, when empty,
+// OptimizableAllowanceTypes.
+type OptimizeRequest struct {
+	Base           CalculationRequest `json:"base"`
+	Budget         decimal.Decimal    `json:"budget" validate:"required,gt=0"`
+	AllowanceTypes []string           `json:"allowanceTypes" validate:"unique,dive,allowance_type_custom_validation"`
+}
+
+// candidates returns the allowance types to allocate to in registration
+// order, which breaks ties in favour of allowances that do not cap the
+// donations registered after them. Per-person allowances are not bought and
+// are left out.
+func (r OptimizeRequest) candidates(defaultDeductions []db.Deduction) []string {
+	allowanceTypes := r.AllowanceTypes
+	if len(allowanceTypes) == 0 {
+		allowanceTypes = OptimizableAllowanceTypes
+	}
+
+	wanted := make(map[string]bool, len(allowanceTypes))
+	for _, allowanceType := range allowanceTypes {
+		wanted[allowanceType] = true
+	}
+
+	var candidates []string
+	for _, allowanceType := range AllowanceTypes() {
+		if wanted[allowanceType] && !getDeductionByType(defaultDeductions, allowanceType).PerPerson {
+			candidates = append(candidates, allowanceType)
+		}
+	}
+
+	return candidates
+}
+
+// CalculationRequest returns the base request claiming the extra amounts
+// on top of its allowances.
+func (r OptimizeRequest) CalculationRequest(extra map[string]decimal.Decimal) CalculationRequest {
+	req := r.Base
+	req.Allowances = append([]Allowance(nil), r.Base.Allowances...)
+	for _, allowanceType := range AllowanceTypes() {
+		if amount := extra[allowanceType]; amount.IsPositive() {
+			req.Allowances = append(req.Allowances, Allowance{AllowanceType: allowanceType, Amount: amount})
+		}
+	}
+
+	return req
+}
+
+// Allocation is the amount the optimizer puts into one allowance type.
+// TaxSaved is the tax that would be paid without it, everything else
+// allocated, and SavingPerBaht is TaxSaved per baht of Amount.
+type Allocation struct {
+	AllowanceType string          `json:"allowanceType"`
+	Amount        decimal.Decimal `json:"amount"`
+	TaxSaved      decimal.Decimal `json:"taxSaved"`
+	SavingPerBaht decimal.Decimal `json:"savingPerBaht"`
+}
+
+// OptimizeResult is the allocation that minimises the tax. Invested is the
+// part of the budget allocated and Unused the part no allowance could
+// deduct. The taxes are before WHT. Result is the calculation with the
+// allocation claimed.
+type OptimizeResult struct {
+	Allocations   []Allocation
+	Invested      decimal.Decimal
+	Unused        decimal.Decimal
+	TaxBefore     decimal.Decimal
+	TaxAfter      decimal.Decimal
+	TaxSaved      decimal.Decimal
+	SavingPerBaht decimal.Decimal
+	Result        Result
+}
+
+// Optimize allocates the budget greedily: every step puts up to
+// optimizeStep into the allowance saving the most tax per baht until the
+// budget or the caps run out. Claims that a later step pushed over a cap,
+// such as donations capped by the income after other allowances, are then
+// trimmed and the freed budget allocated again.
+func Optimize(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, req OptimizeRequest) OptimizeResult {
+	calculate := func(extra map[string]decimal.Decimal) Result {
+		return Calculate(taxBrackets, defaultDeductions, req.CalculationRequest(extra))
+	}
+
+	candidates := req.candidates(defaultDeductions)
+	extra := map[string]decimal.Decimal{}
+	before := calculate(extra)
+	current := before
+	remaining := req.Budget
+
+	for round := 0; round < maxOptimizeRounds; round++ {
+		for remaining.IsPositive() {
+			var best string
+			var bestStep, bestRate decimal.Decimal
+			var bestResult Result
+			for _, allowanceType := range candidates {
+				step := decimal.Min(remaining, optimizeStep, headroom(defaultDeductions, req.CalculationRequest(extra), current, allowanceType))
+				if !step.IsPositive() {
+					continue
+				}
+
+				trial := copyAmounts(extra)
+				trial[allowanceType] = trial[allowanceType].Add(step)
+				res := calculate(trial)
+
+				rate := current.Trace.Tax.Sub(res.Trace.Tax).Div(step)
+				if rate.GreaterThan(bestRate) {
+					best, bestStep, bestRate, bestResult = allowanceType, step, rate, res
+				}
+			}
+
+			if best == "" {
+				break
+			}
+
+			extra[best] = extra[best].Add(bestStep)
+			remaining = remaining.Sub(bestStep)
+			current = bestResult
+		}
+
+		trimmed := trimAllocation(defaultDeductions, req, extra, current)
+		if !trimmed.IsPositive() {
+			break
+		}
+
+		remaining = remaining.Add(trimmed)
+		current = calculate(extra)
+	}
+
+	res := OptimizeResult{
+		Allocations: []Allocation{},
+		Invested:    req.Budget.Sub(remaining),
+		Unused:      remaining,
+		TaxBefore:   before.Trace.Tax,
+		TaxAfter:    current.Trace.Tax,
+		TaxSaved:    before.Trace.Tax.Sub(current.Trace.Tax),
+		Result:      current,
+	}
+	res.SavingPerBaht = savingPerBaht(res.TaxSaved, res.Invested)
+
+	for _, allowanceType := range candidates {
+		amount := extra[allowanceType]
+		if !amount.IsPositive() {
+			continue
+		}
+
+		without := copyAmounts(extra)
+		delete(without, allowanceType)
+		saved := calculate(without).Trace.Tax.Sub(current.Trace.Tax)
+
+		res.Allocations = append(res.Allocations, Allocation{
+			AllowanceType: allowanceType,
+			Amount:        amount,
+			TaxSaved:      saved,
+			SavingPerBaht: savingPerBaht(saved, amount),
+		})
+	}
+
+	return res
+}
+
+// headroom returns how much more of allowanceType may be claimed before
+// its cap is reached, to the satang.
+func headroom(defaultDeductions []db.Deduction, req CalculationRequest, res Result, allowanceType string) decimal.Decimal {
+	allowed, inputs := assessAllowances(defaultDeductions, req, res.Trace.NetIncome.Sub(res.Trace.PersonalAllowance))
+
+	rule := allowanceRules[allowanceType]
+	room := rule.Limit(inputs[allowanceType]).Sub(allowed[allowanceType])
+	if donation, ok := rule.(DonationCap); ok {
+		room = room.Div(donation.Multiplier)
+	}
+
+	return decimal.Max(room.RoundFloor(2), decimal.Zero)
+}
+
+// trimAllocation takes back the part of every extra amount that is claimed
+// but no longer allowed and returns the total taken back.
+func trimAllocation(defaultDeductions []db.Deduction, req OptimizeRequest, extra map[string]decimal.Decimal, res Result) decimal.Decimal {
+	allowed, inputs := assessAllowances(defaultDeductions, req.CalculationRequest(extra), res.Trace.NetIncome.Sub(res.Trace.PersonalAllowance))
+
+	trimmed := decimal.Zero
+	for allowanceType, amount := range extra {
+		needed := allowed[allowanceType]
+		if donation, ok := allowanceRules[allowanceType].(DonationCap); ok {
+			needed = needed.Div(donation.Multiplier).RoundCeil(2)
+		}
+
+		waste := decimal.Min(inputs[allowanceType].Claimed().Sub(needed), amount)
+		if waste.IsPositive() {
+			extra[allowanceType] = amount.Sub(waste)
+			trimmed = trimmed.Add(waste)
+		}
+	}
+
+	return trimmed
+}
+
+func copyAmounts(amounts map[string]decimal.Decimal) map[string]decimal.Decimal {
+	cp := make(map[string]decimal.Decimal, len(amounts))
+	for k, v := range amounts {
+		cp[k] = v
+	}
+
+	return cp
+}
+
+// savingPerBaht returns saved per baht of amount to four decimal places, or
+// zero when nothing was spent.
+func savingPerBaht(saved, amount decimal.Decimal) decimal.Decimal {
+	if !amount.IsPositive() {
+		return decimal.Zero
+	}
+
+	return saved.Div(amount).Round(4)
+}
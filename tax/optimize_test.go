@@ -0,0 +1,129 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestOptimize(t *testing.T) {
+	donationDeductions := []db.Deduction{
+		{Type: "personal", Amount: decimal.NewFromInt(60000)},
+		{Type: "ssf", Amount: decimal.NewFromInt(200000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(30))},
+		{Type: "donation-education", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+		{Type: "donation", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+	}
+
+	testCases := []struct {
+		name              string
+		input             OptimizeRequest
+		deductions        []db.Deduction
+		expectAllocations map[string]decimal.Decimal
+		expectUnused      decimal.Decimal
+		expectTaxAfter    decimal.Decimal
+	}{
+		{
+			name: "Budget within the caps should all be allocated",
+			input: OptimizeRequest{
+				Base:   CalculationRequest{TotalIncome: decimal.NewFromInt(1000000)},
+				Budget: decimal.NewFromInt(100000),
+			},
+			deductions: defaultDeductions,
+			expectAllocations: map[string]decimal.Decimal{
+				"k-receipt": decimal.NewFromInt(50000),
+				"donation":  decimal.NewFromInt(50000),
+			},
+			expectUnused:   decimal.Zero,
+			expectTaxAfter: decimal.NewFromInt(86000),
+		},
+		{
+			name: "Budget over the caps should leave the rest unused",
+			input: OptimizeRequest{
+				Base:   CalculationRequest{TotalIncome: decimal.NewFromInt(1000000)},
+				Budget: decimal.NewFromInt(500000),
+			},
+			deductions: defaultDeductions,
+			expectAllocations: map[string]decimal.Decimal{
+				"k-receipt": decimal.NewFromInt(50000),
+				"donation":  decimal.NewFromInt(100000),
+			},
+			expectUnused:   decimal.NewFromInt(350000),
+			expectTaxAfter: decimal.NewFromInt(78500),
+		},
+		{
+			name: "Allowance types asked for should be the only ones allocated",
+			input: OptimizeRequest{
+				Base:           CalculationRequest{TotalIncome: decimal.NewFromInt(1000000)},
+				Budget:         decimal.NewFromInt(100000),
+				AllowanceTypes: []string{"donation"},
+			},
+			deductions: defaultDeductions,
+			expectAllocations: map[string]decimal.Decimal{
+				"donation": decimal.NewFromInt(100000),
+			},
+			expectUnused:   decimal.Zero,
+			expectTaxAfter: decimal.NewFromInt(86000),
+		},
+		{
+			name: "Education donations capped by a later SSF should be trimmed",
+			input: OptimizeRequest{
+				Base:   CalculationRequest{TotalIncome: decimal.NewFromInt(1000000)},
+				Budget: decimal.NewFromInt(300000),
+			},
+			deductions: donationDeductions,
+			expectAllocations: map[string]decimal.Decimal{
+				"ssf":                decimal.NewFromInt(200000),
+				"donation-education": decimal.NewFromInt(37000),
+			},
+			expectUnused:   decimal.NewFromInt(63000),
+			expectTaxAfter: decimal.NewFromInt(59900),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Optimize(defaultTaxBrackets, tc.deductions, tc.input)
+
+			if len(res.Allocations) != len(tc.expectAllocations) {
+				t.Fatalf("Expected %d allocations, got %v", len(tc.expectAllocations), res.Allocations)
+			}
+
+			for _, allocation := range res.Allocations {
+				expected, ok := tc.expectAllocations[allocation.AllowanceType]
+				if !ok || !allocation.Amount.Equal(expected) {
+					t.Errorf("Expected %s allocation %v, got %v", allocation.AllowanceType, expected, allocation.Amount)
+				}
+			}
+
+			if !res.Unused.Equal(tc.expectUnused) {
+				t.Errorf("Expected unused %v, got %v", tc.expectUnused, res.Unused)
+			}
+
+			if !res.TaxAfter.Equal(tc.expectTaxAfter) {
+				t.Errorf("Expected tax after %v, got %v", tc.expectTaxAfter, res.TaxAfter)
+			}
+
+			if !res.TaxSaved.Equal(res.TaxBefore.Sub(res.TaxAfter)) {
+				t.Errorf("Expected tax saved %v, got %v", res.TaxBefore.Sub(res.TaxAfter), res.TaxSaved)
+			}
+		})
+	}
+}
+
+func TestOptimizeSavingPerBaht(t *testing.T) {
+	res := Optimize(defaultTaxBrackets, defaultDeductions, OptimizeRequest{
+		Base:   CalculationRequest{TotalIncome: decimal.NewFromInt(1000000)},
+		Budget: decimal.NewFromInt(100000),
+	})
+
+	for _, allocation := range res.Allocations {
+		if !allocation.SavingPerBaht.Equal(decimal.RequireFromString("0.15")) {
+			t.Errorf("Expected %s to save 0.15 per baht, got %v", allocation.AllowanceType, allocation.SavingPerBaht)
+		}
+	}
+
+	if !res.SavingPerBaht.Equal(decimal.RequireFromString("0.15")) {
+		t.Errorf("Expected 0.15 saved per baht, got %v", res.SavingPerBaht)
+	}
+}
@@ -0,0 +1,173 @@
+package tax
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	surchargeRate         = decimal.NewFromInt(15).Shift(-3)
+	installmentThreshold  = decimal.NewFromInt(3000)
+	installmentCount      = 3
+	latePenaltyGraceDays  = 7
+	latePenaltyWithinDays = decimal.NewFromInt(100)
+	latePenalty           = decimal.NewFromInt(200)
+)
+
+// PaymentPlanRequest asks how the tax of Calculation is settled when the
+// return is filed on FilingDate and paid on PaymentDate, in installments
+// when asked for and allowed. FilingDate defaults to today, PaymentDate to
+// FilingDate and the tax year of Calculation to the year before FilingDate.
+type PaymentPlanRequest struct {
+	Calculation  CalculationRequest `json:"calculation"`
+	FilingDate   Date               `json:"filingDate"`
+	PaymentDate  Date               `json:"paymentDate"`
+	Installments bool               `json:"installments"`
+}
+
+// TaxCalculation returns Calculation, for the tax year before the filing
+// date unless its tax year or AsOf is given.
+func (r PaymentPlanRequest) TaxCalculation() CalculationRequest {
+	return r.Calculation.FiledOn(r.FiledOn())
+}
+
+// FiledOn returns the filing date, defaulting to today.
+func (r PaymentPlanRequest) FiledOn() Date {
+	if r.FilingDate.IsZero() {
+		return Today()
+	}
+
+	return r.FilingDate
+}
+
+// PaidOn returns the payment date, defaulting to the filing date.
+func (r PaymentPlanRequest) PaidOn() Date {
+	if r.PaymentDate.IsZero() {
+		return r.FiledOn()
+	}
+
+	return r.PaymentDate
+}
+
+// Payment is one payment of a plan.
+type Payment struct {
+	Installment int             `json:"installment"`
+	DueDate     Date            `json:"dueDate"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// PaymentPlan settles the tax owed, with the surcharge and penalty of
+// paying after Deadline.
+type PaymentPlan struct {
+	Tax      decimal.Decimal `json:"tax"`
+	Deadline Date            `json:"deadline"`
+	// MonthsLate counts every month or part of a month paid after Deadline,
+	// each adding 1.5% of the tax to Surcharge, up to the tax itself.
+	MonthsLate int             `json:"monthsLate"`
+	Surcharge  decimal.Decimal `json:"surcharge"`
+	// Penalty is the fixed fine for filing after Deadline.
+	Penalty      decimal.Decimal `json:"penalty"`
+	Total        decimal.Decimal `json:"total"`
+	Installments bool            `json:"installments"` // paid in three installments
+	Payments     []Payment       `json:"payments"`
+}
+
+// FiledOn returns r filed on filedOn: for the tax year before it unless the
+// tax year or AsOf of r is given.
+func (r CalculationRequest) FiledOn(filedOn Date) CalculationRequest {
+	if r.TaxYear == 0 && r.AsOf.IsZero() {
+		r.TaxYear = filedOn.Year() - 1
+	}
+
+	return r
+}
+
+// FilingDeadline returns the last day to file and pay the tax of taxYear,
+// 31 March of the year after.
+func FilingDeadline(taxYear int) Date {
+	return Date{time.Date(taxYear+1, time.March, 31, 0, 0, 0, 0, time.UTC)}
+}
+
+// PlanPayment works out how tax, the tax owed after credits, is paid.
+// Filing up to 7 days late is fined 100 baht and later 200 baht. A tax of
+// 3,000 baht or more filed on time may be paid in three equal
+// installments: on filing and one and two months after the deadline, the
+// last absorbing the rounding. Late returns are paid in full on the
+// payment date with the surcharge.
+func PlanPayment(tax decimal.Decimal, taxYear int, req PaymentPlanRequest) PaymentPlan {
+	deadline := FilingDeadline(taxYear)
+	plan := PaymentPlan{
+		Tax:       tax,
+		Deadline:  deadline,
+		Surcharge: decimal.Zero,
+		Penalty:   decimal.Zero,
+		Payments:  []Payment{},
+	}
+
+	filedOn := req.FiledOn()
+	if filedOn.After(deadline.Time) {
+		plan.Penalty = latePenalty
+		if !filedOn.After(deadline.AddDate(0, 0, latePenaltyGraceDays)) {
+			plan.Penalty = latePenaltyWithinDays
+		}
+	}
+
+	if tax.IsPositive() {
+		plan.MonthsLate = monthsAfter(deadline, req.PaidOn())
+		surcharge := tax.Mul(surchargeRate).Mul(decimal.NewFromInt(int64(plan.MonthsLate)))
+		plan.Surcharge = roundMoney(decimal.Min(surcharge, tax))
+	}
+
+	plan.Total = tax.Add(plan.Surcharge).Add(plan.Penalty)
+	if !plan.Total.IsPositive() {
+		return plan
+	}
+
+	plan.Installments = req.Installments && plan.Penalty.IsZero() && plan.Surcharge.IsZero() &&
+		!tax.LessThan(installmentThreshold)
+	if !plan.Installments {
+		plan.Payments = append(plan.Payments, Payment{Installment: 1, DueDate: req.PaidOn(), Amount: plan.Total})
+		return plan
+	}
+
+	installment := roundMoney(tax.Div(decimal.NewFromInt(int64(installmentCount))))
+	remaining := tax
+	for i := 1; i <= installmentCount; i++ {
+		payment := Payment{Installment: i, DueDate: filedOn, Amount: installment}
+		if i > 1 {
+			payment.DueDate = addMonths(deadline, i-1)
+		}
+		if i == installmentCount {
+			payment.Amount = remaining
+		}
+
+		remaining = remaining.Sub(payment.Amount)
+		plan.Payments = append(plan.Payments, payment)
+	}
+
+	return plan
+}
+
+// monthsAfter returns the number of months or parts of a month from
+// deadline to date, zero when date is not after deadline.
+func monthsAfter(deadline, date Date) int {
+	months := 0
+	for addMonths(deadline, months).Before(date.Time) {
+		months++
+	}
+
+	return months
+}
+
+// addMonths adds months to date, keeping to the last day of the month when
+// the day does not exist, e.g. 31 March plus one month is 30 April.
+func addMonths(date Date, months int) Date {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	day := date.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return Date{first.AddDate(0, 0, day-1)}
+}
@@ -0,0 +1,171 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func date(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestPlanPayment(t *testing.T) {
+	testCases := []struct {
+		name            string
+		tax             decimal.Decimal
+		input           PaymentPlanRequest
+		expectMonths    int
+		expectSurcharge decimal.Decimal
+		expectPenalty   decimal.Decimal
+		expectPayments  []Payment
+	}{
+		{
+			name:            "Tax filed on time should be paid in three installments",
+			tax:             decimal.NewFromInt(9000),
+			input:           PaymentPlanRequest{FilingDate: date(2025, time.March, 1), Installments: true},
+			expectSurcharge: decimal.Zero,
+			expectPenalty:   decimal.Zero,
+			expectPayments: []Payment{
+				{Installment: 1, DueDate: date(2025, time.March, 1), Amount: decimal.NewFromInt(3000)},
+				{Installment: 2, DueDate: date(2025, time.April, 30), Amount: decimal.NewFromInt(3000)},
+				{Installment: 3, DueDate: date(2025, time.May, 31), Amount: decimal.NewFromInt(3000)},
+			},
+		},
+		{
+			name:            "Last installment should absorb the rounding",
+			tax:             decimal.NewFromInt(10000),
+			input:           PaymentPlanRequest{FilingDate: date(2025, time.March, 31), Installments: true},
+			expectSurcharge: decimal.Zero,
+			expectPenalty:   decimal.Zero,
+			expectPayments: []Payment{
+				{Installment: 1, DueDate: date(2025, time.March, 31), Amount: decimal.RequireFromString("3333.33")},
+				{Installment: 2, DueDate: date(2025, time.April, 30), Amount: decimal.RequireFromString("3333.33")},
+				{Installment: 3, DueDate: date(2025, time.May, 31), Amount: decimal.RequireFromString("3333.34")},
+			},
+		},
+		{
+			name:            "Tax below 3,000 should be paid at once",
+			tax:             decimal.NewFromInt(2000),
+			input:           PaymentPlanRequest{FilingDate: date(2025, time.March, 1), Installments: true},
+			expectSurcharge: decimal.Zero,
+			expectPenalty:   decimal.Zero,
+			expectPayments: []Payment{
+				{Installment: 1, DueDate: date(2025, time.March, 1), Amount: decimal.NewFromInt(2000)},
+			},
+		},
+		{
+			name:            "Filing within 7 days late should add a month of surcharge and the lower fine",
+			tax:             decimal.NewFromInt(10000),
+			input:           PaymentPlanRequest{FilingDate: date(2025, time.April, 5), Installments: true},
+			expectMonths:    1,
+			expectSurcharge: decimal.NewFromInt(150),
+			expectPenalty:   decimal.NewFromInt(100),
+			expectPayments: []Payment{
+				{Installment: 1, DueDate: date(2025, time.April, 5), Amount: decimal.NewFromInt(10250)},
+			},
+		},
+		{
+			name:            "Part of a month late should count as a month",
+			tax:             decimal.NewFromInt(10000),
+			input:           PaymentPlanRequest{FilingDate: date(2025, time.April, 5), PaymentDate: date(2025, time.June, 15)},
+			expectMonths:    3,
+			expectSurcharge: decimal.NewFromInt(450),
+			expectPenalty:   decimal.NewFromInt(100),
+			expectPayments: []Payment{
+				{Installment: 1, DueDate: date(2025, time.June, 15), Amount: decimal.NewFromInt(10550)},
+			},
+		},
+		{
+			name:            "Surcharge should be capped at the tax",
+			tax:             decimal.NewFromInt(10000),
+			input:           PaymentPlanRequest{FilingDate: date(2031, time.April, 1)},
+			expectMonths:    73,
+			expectSurcharge: decimal.NewFromInt(10000),
+			expectPenalty:   decimal.NewFromInt(200),
+			expectPayments: []Payment{
+				{Installment: 1, DueDate: date(2031, time.April, 1), Amount: decimal.NewFromInt(20200)},
+			},
+		},
+		{
+			name:            "No tax owed on time should need no payment",
+			tax:             decimal.Zero,
+			input:           PaymentPlanRequest{FilingDate: date(2025, time.March, 1)},
+			expectSurcharge: decimal.Zero,
+			expectPenalty:   decimal.Zero,
+			expectPayments:  []Payment{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := PlanPayment(tc.tax, 2024, tc.input)
+
+			if !plan.Deadline.Equal(date(2025, time.March, 31).Time) {
+				t.Errorf("Expected deadline 2025-03-31, got %v", plan.Deadline)
+			}
+
+			if plan.MonthsLate != tc.expectMonths {
+				t.Errorf("Expected %d months late, got %d", tc.expectMonths, plan.MonthsLate)
+			}
+
+			if !plan.Surcharge.Equal(tc.expectSurcharge) {
+				t.Errorf("Expected surcharge %v, got %v", tc.expectSurcharge, plan.Surcharge)
+			}
+
+			if !plan.Penalty.Equal(tc.expectPenalty) {
+				t.Errorf("Expected penalty %v, got %v", tc.expectPenalty, plan.Penalty)
+			}
+
+			if len(plan.Payments) != len(tc.expectPayments) {
+				t.Fatalf("Expected %d payments, got %v", len(tc.expectPayments), plan.Payments)
+			}
+
+			total := decimal.Zero
+			for i, expect := range tc.expectPayments {
+				got := plan.Payments[i]
+				if got.Installment != expect.Installment || !got.DueDate.Equal(expect.DueDate.Time) || !got.Amount.Equal(expect.Amount) {
+					t.Errorf("Expected payment %+v, got %+v", expect, got)
+				}
+				total = total.Add(got.Amount)
+			}
+
+			if !total.Equal(plan.Total) {
+				t.Errorf("Expected payments to add up to %v, got %v", plan.Total, total)
+			}
+		})
+	}
+}
+
+func TestPaymentPlanRequestTaxCalculation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      PaymentPlanRequest
+		expectYear int
+	}{
+		{
+			name:       "Tax year should default to the year before filing",
+			input:      PaymentPlanRequest{FilingDate: date(2025, time.March, 1)},
+			expectYear: 2024,
+		},
+		{
+			name:       "Given tax year should be kept",
+			input:      PaymentPlanRequest{Calculation: CalculationRequest{TaxYear: 2020}, FilingDate: date(2025, time.March, 1)},
+			expectYear: 2020,
+		},
+		{
+			name:       "Tax year of asOf should be kept",
+			input:      PaymentPlanRequest{Calculation: CalculationRequest{AsOf: date(2023, time.June, 1)}, FilingDate: date(2025, time.March, 1)},
+			expectYear: 2023,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.input.TaxCalculation().Year(); got != tc.expectYear {
+				t.Errorf("Expected %v, got %v", tc.expectYear, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package tax
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// RefundStatus is where a refund claim stands.
+type RefundStatus string
+
+const (
+	// RefundSubmitted is a claim waiting to be reviewed.
+	RefundSubmitted RefundStatus = "submitted"
+	// RefundUnderReview is a claim being reviewed.
+	RefundUnderReview RefundStatus = "under_review"
+	// RefundPaid is a claim paid out; it cannot move on.
+	RefundPaid RefundStatus = "paid"
+)
+
+// refundTransitions maps each status to the one a claim moves on to.
+var refundTransitions = map[RefundStatus]RefundStatus{
+	RefundSubmitted:   RefundUnderReview,
+	RefundUnderReview: RefundPaid,
+}
+
+var (
+	refundWindowMonths = 3
+	refundInterestRate = decimal.NewFromInt(1).Shift(-2)
+)
+
+// IsRefundStatus reports whether status is a refund status.
+func IsRefundStatus(status string) bool {
+	switch RefundStatus(status) {
+	case RefundSubmitted, RefundUnderReview, RefundPaid:
+		return true
+	}
+
+	return false
+}
+
+// MoveRefund checks that a claim may move from status from to status to:
+// submitted claims go under review and claims under review get paid.
+func MoveRefund(from, to RefundStatus) error {
+	if next, ok := refundTransitions[from]; !ok || next != to {
+		return fmt.Errorf("refund claim cannot move from %s to %s", from, to)
+	}
+
+	return nil
+}
+
+// RefundDueDate returns the last day a refund of taxYear filed on filedOn
+// may be paid without interest: three months after the filing deadline or
+// the filing date, whichever is later.
+func RefundDueDate(taxYear int, filedOn Date) Date {
+	start := FilingDeadline(taxYear)
+	if filedOn.After(start.Time) {
+		start = filedOn
+	}
+
+	return addMonths(start, refundWindowMonths)
+}
+
+// RefundInterest returns the months or parts of a month a refund of taxYear
+// filed on filedOn is paid on paidOn after its due date, and the interest
+// of 1% of the refund for each of them, up to the refund itself.
+func RefundInterest(refund decimal.Decimal, taxYear int, filedOn, paidOn Date) (int, decimal.Decimal) {
+	months := monthsAfter(RefundDueDate(taxYear, filedOn), paidOn)
+	interest := refund.Mul(refundInterestRate).Mul(decimal.NewFromInt(int64(months)))
+
+	return months, roundMoney(decimal.Min(interest, refund))
+}
@@ -0,0 +1,93 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMoveRefund(t *testing.T) {
+	testCases := []struct {
+		from, to  RefundStatus
+		expectErr bool
+	}{
+		{RefundSubmitted, RefundUnderReview, false},
+		{RefundUnderReview, RefundPaid, false},
+		{RefundSubmitted, RefundPaid, true},
+		{RefundUnderReview, RefundSubmitted, true},
+		{RefundPaid, RefundSubmitted, true},
+		{RefundPaid, RefundPaid, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.from)+" to "+string(tc.to), func(t *testing.T) {
+			err := MoveRefund(tc.from, tc.to)
+			if (err != nil) != tc.expectErr {
+				t.Errorf("Expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestRefundInterest(t *testing.T) {
+	refund := decimal.NewFromInt(10000)
+
+	testCases := []struct {
+		name           string
+		filedOn        Date
+		paidOn         Date
+		expectDueDate  Date
+		expectMonths   int
+		expectInterest decimal.Decimal
+	}{
+		{
+			name:           "Refund paid within three months of the deadline should earn no interest",
+			filedOn:        date(2025, time.February, 1),
+			paidOn:         date(2025, time.June, 30),
+			expectDueDate:  date(2025, time.June, 30),
+			expectInterest: decimal.Zero,
+		},
+		{
+			name:           "Part of a month late should earn a month of interest",
+			filedOn:        date(2025, time.February, 1),
+			paidOn:         date(2025, time.July, 1),
+			expectDueDate:  date(2025, time.June, 30),
+			expectMonths:   1,
+			expectInterest: decimal.NewFromInt(100),
+		},
+		{
+			name:           "Return filed after the deadline should count from the filing date",
+			filedOn:        date(2025, time.May, 15),
+			paidOn:         date(2025, time.October, 1),
+			expectDueDate:  date(2025, time.August, 15),
+			expectMonths:   2,
+			expectInterest: decimal.NewFromInt(200),
+		},
+		{
+			name:           "Interest should be capped at the refund",
+			filedOn:        date(2025, time.February, 1),
+			paidOn:         date(2034, time.January, 1),
+			expectDueDate:  date(2025, time.June, 30),
+			expectMonths:   103,
+			expectInterest: decimal.NewFromInt(10000),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if dueDate := RefundDueDate(2024, tc.filedOn); !dueDate.Equal(tc.expectDueDate.Time) {
+				t.Errorf("Expected due date %v, got %v", tc.expectDueDate, dueDate)
+			}
+
+			months, interest := RefundInterest(refund, 2024, tc.filedOn, tc.paidOn)
+			if months != tc.expectMonths {
+				t.Errorf("Expected %d months, got %d", tc.expectMonths, months)
+			}
+
+			if !interest.Equal(tc.expectInterest) {
+				t.Errorf("Expected interest %v, got %v", tc.expectInterest, interest)
+			}
+		})
+	}
+}
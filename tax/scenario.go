@@ -0,0 +1,82 @@
+package tax
+
+import (
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+// ScenarioRequest compares Base with named variations of it. Every
+// calculation uses the deductions and brackets of the base request.
+type ScenarioRequest struct {
+	Base      CalculationRequest `json:"base"`
+	Scenarios []Scenario         `json:"scenarios" validate:"required,min=1,max=20,unique=Name,dive"`
+}
+
+// Scenario is a variation of the base request: its allowances are claimed
+// on top of the base allowances, and TotalIncome and Wht replace those of
+// the base when set. TotalIncome replaces an income broken down by
+// category too.
+type Scenario struct {
+	Name        string              `json:"name" validate:"required"`
+	TotalIncome decimal.NullDecimal `json:"totalIncome"`
+	Wht         decimal.NullDecimal `json:"wht"`
+	Allowances  []Allowance         `json:"allowances" validate:"dive"`
+}
+
+// Apply returns the base request varied by s.
+func (s Scenario) Apply(base CalculationRequest) CalculationRequest {
+	req := base
+	if s.TotalIncome.Valid {
+		req.TotalIncome = s.TotalIncome.Decimal
+		req.Incomes = nil
+	}
+
+	if s.Wht.Valid {
+		req.Wht = s.Wht.Decimal
+	}
+
+	req.Allowances = append(append([]Allowance(nil), base.Allowances...), s.Allowances...)
+
+	return req
+}
+
+// ScenarioDelta is how a scenario differs from the base, scenario minus
+// base.
+type ScenarioDelta struct {
+	Tax             decimal.Decimal `json:"tax"`
+	TaxRefund       decimal.Decimal `json:"taxRefund"`
+	TaxableIncome   decimal.Decimal `json:"taxableIncome"`
+	TotalAllowances decimal.Decimal `json:"totalAllowances"`
+	NetIncome       decimal.Decimal `json:"netIncome"`
+}
+
+// ScenarioResult is the calculation of one scenario and its delta.
+type ScenarioResult struct {
+	Name   string
+	Result Result
+	Delta  ScenarioDelta
+}
+
+// CompareScenarios calculates the base request and every scenario of req,
+// in request order.
+func CompareScenarios(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, req ScenarioRequest) (Result, []ScenarioResult) {
+	base := Calculate(taxBrackets, defaultDeductions, req.Base)
+
+	results := make([]ScenarioResult, 0, len(req.Scenarios))
+	for _, scenario := range req.Scenarios {
+		res := Calculate(taxBrackets, defaultDeductions, scenario.Apply(req.Base))
+		results = append(results, ScenarioResult{
+			Name:   scenario.Name,
+			Result: res,
+			Delta: ScenarioDelta{
+				Tax:             res.Tax.Sub(base.Tax),
+				TaxRefund:       res.TaxRefund.Sub(base.TaxRefund),
+				TaxableIncome:   res.TaxableIncome.Sub(base.TaxableIncome),
+				TotalAllowances: res.TotalAllowances.Sub(base.TotalAllowances),
+				NetIncome:       res.NetIncome.Sub(base.NetIncome),
+			},
+		})
+	}
+
+	return base, results
+}
@@ -0,0 +1,105 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCompareScenarios(t *testing.T) {
+	req := ScenarioRequest{
+		Base: CalculationRequest{TotalIncome: decimal.NewFromInt(500000)},
+		Scenarios: []Scenario{
+			{
+				Name: "donate",
+				Allowances: []Allowance{
+					{AllowanceType: "donation", Amount: decimal.NewFromInt(100000)},
+				},
+			},
+			{Name: "raise", TotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(600000))},
+			{Name: "withheld", Wht: decimal.NewNullDecimal(decimal.NewFromInt(30000))},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		expectTax   decimal.Decimal
+		expectDelta ScenarioDelta
+	}{
+		{
+			name:      "donate",
+			expectTax: decimal.NewFromInt(19000),
+			expectDelta: ScenarioDelta{
+				Tax:             decimal.NewFromInt(-10000),
+				TaxRefund:       decimal.Zero,
+				TaxableIncome:   decimal.NewFromInt(-100000),
+				TotalAllowances: decimal.NewFromInt(100000),
+				NetIncome:       decimal.NewFromInt(10000),
+			},
+		},
+		{
+			name:      "raise",
+			expectTax: decimal.NewFromInt(41000),
+			expectDelta: ScenarioDelta{
+				Tax:             decimal.NewFromInt(12000),
+				TaxRefund:       decimal.Zero,
+				TaxableIncome:   decimal.NewFromInt(100000),
+				TotalAllowances: decimal.Zero,
+				NetIncome:       decimal.NewFromInt(88000),
+			},
+		},
+		{
+			name:      "withheld",
+			expectTax: decimal.Zero,
+			expectDelta: ScenarioDelta{
+				Tax:             decimal.NewFromInt(-29000),
+				TaxRefund:       decimal.NewFromInt(1000),
+				TaxableIncome:   decimal.Zero,
+				TotalAllowances: decimal.Zero,
+				NetIncome:       decimal.Zero,
+			},
+		},
+	}
+
+	base, results := CompareScenarios(defaultTaxBrackets, defaultDeductions, req)
+	if !base.Tax.Equal(decimal.NewFromInt(29000)) {
+		t.Errorf("Expected base tax 29000, got %v", base.Tax)
+	}
+
+	if len(results) != len(testCases) {
+		t.Fatalf("Expected %d scenarios, got %d", len(testCases), len(results))
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := results[i]
+			if res.Name != tc.name {
+				t.Errorf("Expected scenario %q, got %q", tc.name, res.Name)
+			}
+
+			if !res.Result.Tax.Equal(tc.expectTax) {
+				t.Errorf("Expected tax %v, got %v", tc.expectTax, res.Result.Tax)
+			}
+
+			delta := res.Delta
+			for _, field := range []struct {
+				name             string
+				expected, actual decimal.Decimal
+			}{
+				{"tax", tc.expectDelta.Tax, delta.Tax},
+				{"taxRefund", tc.expectDelta.TaxRefund, delta.TaxRefund},
+				{"taxableIncome", tc.expectDelta.TaxableIncome, delta.TaxableIncome},
+				{"totalAllowances", tc.expectDelta.TotalAllowances, delta.TotalAllowances},
+				{"netIncome", tc.expectDelta.NetIncome, delta.NetIncome},
+			} {
+				if !field.actual.Equal(field.expected) {
+					t.Errorf("Expected %s delta %v, got %v", field.name, field.expected, field.actual)
+				}
+			}
+		})
+	}
+
+	if len(req.Base.Allowances) != 0 {
+		t.Errorf("Expected the base allowances to be left alone, got %v", req.Base.Allowances)
+	}
+}
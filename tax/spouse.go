@@ -0,0 +1,202 @@
+package tax
+
+import (
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+// FilingMethod names how a married couple files.
+type FilingMethod string
+
+const (
+	// FilingSeparate files one return for each spouse.
+	FilingSeparate FilingMethod = "separate"
+	// FilingJoint files every income of both spouses in one return.
+	FilingJoint FilingMethod = "joint"
+	// FilingJointSalarySeparated files the 40(1) salary of the spouse in a
+	// return of its own and every other income jointly.
+	FilingJointSalarySeparated FilingMethod = "joint-salary-separated"
+)
+
+// Spouse is the income, WHT and allowances of the spouse of the taxpayer.
+// Like a CalculationRequest it takes either TotalIncome or Incomes, both of
+// which may be left out for a spouse without income.
+type Spouse struct {
+	TotalIncome decimal.Decimal `json:"totalIncome" validate:"excluded_with=Incomes,min=0.0"`
+	Incomes     []Income        `json:"incomes,omitempty" validate:"dive"`
+	Wht         decimal.Decimal `json:"wht" validate:"wht_custom_validation"`
+	Allowances  []Allowance     `json:"allowances" validate:"dive"`
+}
+
+// GrossIncome returns TotalIncome, or the sum of Incomes when broken down.
+func (s Spouse) GrossIncome() decimal.Decimal {
+	return CalculationRequest{TotalIncome: s.TotalIncome, Incomes: s.Incomes}.GrossIncome()
+}
+
+// FilingReturn is one of the returns filed under a filing method.
+type FilingReturn struct {
+	Filer         string          `json:"filer"`
+	GrossIncome   decimal.Decimal `json:"grossIncome"`
+	TaxableIncome decimal.Decimal `json:"taxableIncome"`
+	Tax           decimal.Decimal `json:"tax"`
+}
+
+// FilingOption is the tax of the household under one filing method.
+// HouseholdTax is the tax of every return before WHT; TaxPayable and
+// TaxRefund settle it against the WHT of both spouses.
+type FilingOption struct {
+	Method       FilingMethod    `json:"method"`
+	Returns      []FilingReturn  `json:"returns"`
+	HouseholdTax decimal.Decimal `json:"householdTax"`
+	TaxPayable   decimal.Decimal `json:"taxPayable"`
+	TaxRefund    decimal.Decimal `json:"taxRefund"`
+}
+
+// FilingComparison lists the filing methods open to the couple and
+// recommends the one with the lowest household tax.
+type FilingComparison struct {
+	Options     []FilingOption `json:"options"`
+	Recommended FilingMethod   `json:"recommended"`
+}
+
+// compareFilings works out every filing method open to the couple of req.
+// Separate filing is always open. Joint filing needs both incomes given
+// the same way, as a total or broken down, and claims the spouse allowance
+// in place of the personal allowance of the spouse. Separating the salary
+// of the spouse also needs the spouse to have 40(1) income. Each spouse
+// deducts the expenses of their own incomes on a joint return. The spouse
+// allowance is claimed on a separate return when, and only when, the spouse
+// has no income, whether or not req claims it, so that both methods are
+// compared on the same allowances. On a tie the method listed first is
+// recommended.
+func compareFilings(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, req CalculationRequest) FilingComparison {
+	spouse := *req.Spouse
+
+	taxpayer := withoutSpouseAllowance(req)
+	spouseReq := CalculationRequest{
+		TotalIncome: spouse.TotalIncome,
+		Incomes:     spouse.Incomes,
+		Wht:         spouse.Wht,
+		Allowances:  spouse.Allowances,
+		Rounding:    req.Rounding,
+		TaxYear:     req.TaxYear,
+		AsOf:        req.AsOf,
+	}
+
+	if !spouseReq.GrossIncome().IsPositive() {
+		taxpayer.Allowances = append(taxpayer.Allowances, Allowance{AllowanceType: "spouse"})
+	}
+
+	var options []FilingOption
+	options = append(options, newFilingOption(taxBrackets, defaultDeductions, FilingSeparate,
+		filingRequest{"taxpayer", taxpayer},
+		filingRequest{"spouse", spouseReq},
+	))
+
+	if (len(req.Incomes) == 0) == (len(spouse.Incomes) == 0) {
+		joint := withoutSpouseAllowance(req)
+		joint.TotalIncome = req.TotalIncome.Add(spouse.TotalIncome)
+		joint.Incomes = append(append([]Income(nil), req.Incomes...), spouseIncomes(spouse.Incomes)...)
+		joint.Wht = req.Wht.Add(spouse.Wht)
+		joint.Allowances = append(append(joint.Allowances, spouse.Allowances...), Allowance{AllowanceType: "spouse"})
+		if len(joint.Incomes) == 0 {
+			joint.Incomes = nil
+		}
+
+		options = append(options, newFilingOption(taxBrackets, defaultDeductions, FilingJoint,
+			filingRequest{"joint", joint},
+		))
+
+		var salaries, others []Income
+		for _, income := range spouse.Incomes {
+			if income.Category == "40(1)" {
+				salaries = append(salaries, income)
+			} else {
+				others = append(others, income)
+			}
+		}
+
+		if len(salaries) > 0 {
+			combined := withoutSpouseAllowance(req)
+			combined.Incomes = append(append([]Income(nil), req.Incomes...), spouseIncomes(others)...)
+
+			salary := spouseReq
+			salary.Incomes = salaries
+
+			options = append(options, newFilingOption(taxBrackets, defaultDeductions, FilingJointSalarySeparated,
+				filingRequest{"joint", combined},
+				filingRequest{"spouse", salary},
+			))
+		}
+	}
+
+	comparison := FilingComparison{Options: options, Recommended: options[0].Method}
+	cheapest := options[0].HouseholdTax
+	for _, option := range options[1:] {
+		if option.HouseholdTax.LessThan(cheapest) {
+			comparison.Recommended, cheapest = option.Method, option.HouseholdTax
+		}
+	}
+
+	return comparison
+}
+
+// filingRequest is the return of one filer.
+type filingRequest struct {
+	filer string
+	req   CalculationRequest
+}
+
+// newFilingOption calculates the returns filed under method.
+func newFilingOption(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, method FilingMethod, requests ...filingRequest) FilingOption {
+	option := FilingOption{Method: method, Returns: []FilingReturn{}}
+
+	payable, refund := decimal.Zero, decimal.Zero
+	for _, r := range requests {
+		res := Calculate(taxBrackets, defaultDeductions, r.req)
+		option.Returns = append(option.Returns, FilingReturn{
+			Filer:         r.filer,
+			GrossIncome:   res.Trace.GrossIncome,
+			TaxableIncome: res.TaxableIncome,
+			Tax:           res.Trace.Tax,
+		})
+		option.HouseholdTax = option.HouseholdTax.Add(res.Trace.Tax)
+		payable = payable.Add(res.Tax)
+		refund = refund.Add(res.TaxRefund)
+	}
+
+	if payable.GreaterThanOrEqual(refund) {
+		option.TaxPayable, option.TaxRefund = payable.Sub(refund), decimal.Zero
+	} else {
+		option.TaxPayable, option.TaxRefund = decimal.Zero, refund.Sub(payable)
+	}
+
+	return option
+}
+
+// spouseIncomes returns a copy of the incomes of the spouse marked to deduct
+// their expenses apart from those of the taxpayer on a joint return.
+func spouseIncomes(incomes []Income) []Income {
+	marked := make([]Income, len(incomes))
+	for i, income := range incomes {
+		income.spouse = true
+		marked[i] = income
+	}
+
+	return marked
+}
+
+// withoutSpouseAllowance returns req, for the taxpayer alone, without its
+// spouse and any claim of the spouse allowance.
+func withoutSpouseAllowance(req CalculationRequest) CalculationRequest {
+	r := req
+	r.Spouse = nil
+	r.Allowances = nil
+	for _, allowance := range req.Allowances {
+		if allowance.AllowanceType != "spouse" {
+			r.Allowances = append(r.Allowances, allowance)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,170 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCompareFilings(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             CalculationRequest
+		expectHousehold   map[FilingMethod]decimal.Decimal
+		expectRecommended FilingMethod
+	}{
+		{
+			name: "Spouse without income should tie with the spouse allowance claimed separately",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(1000000),
+				Allowances:  []Allowance{{AllowanceType: "spouse"}},
+				Spouse:      &Spouse{},
+			},
+			expectHousehold: map[FilingMethod]decimal.Decimal{
+				FilingSeparate: decimal.NewFromInt(92000),
+				FilingJoint:    decimal.NewFromInt(92000),
+			},
+			expectRecommended: FilingSeparate,
+		},
+		{
+			name: "Spouse without income should tie without the spouse allowance claimed",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(1000000),
+				Spouse:      &Spouse{},
+			},
+			expectHousehold: map[FilingMethod]decimal.Decimal{
+				FilingSeparate: decimal.NewFromInt(92000),
+				FilingJoint:    decimal.NewFromInt(92000),
+			},
+			expectRecommended: FilingSeparate,
+		},
+		{
+			name: "Spouse with a low business income should file jointly",
+			input: CalculationRequest{
+				Incomes: []Income{{Category: "40(1)", Amount: decimal.NewFromInt(2000000)}},
+				Spouse: &Spouse{
+					Incomes: []Income{{Category: "40(8)", Amount: decimal.NewFromInt(100000)}},
+				},
+			},
+			expectHousehold: map[FilingMethod]decimal.Decimal{
+				FilingSeparate: decimal.NewFromInt(278000),
+				FilingJoint:    decimal.NewFromInt(274000),
+			},
+			expectRecommended: FilingJoint,
+		},
+		{
+			name: "Spouse with a salary should be offered to separate it",
+			input: CalculationRequest{
+				Incomes: []Income{{Category: "40(1)", Amount: decimal.NewFromInt(2000000)}},
+				Spouse: &Spouse{
+					Incomes: []Income{
+						{Category: "40(1)", Amount: decimal.NewFromInt(1000000)},
+						{Category: "40(8)", Amount: decimal.NewFromInt(100000)},
+					},
+				},
+			},
+			expectHousehold: map[FilingMethod]decimal.Decimal{
+				FilingSeparate:             decimal.NewFromInt(370000),
+				FilingJoint:                decimal.NewFromInt(562000),
+				FilingJointSalarySeparated: decimal.NewFromInt(372000),
+			},
+			expectRecommended: FilingSeparate,
+		},
+		{
+			name: "Spouse with a salary and a 40(2) income should deduct its own expenses jointly",
+			input: CalculationRequest{
+				Incomes: []Income{{Category: "40(1)", Amount: decimal.NewFromInt(2000000)}},
+				Spouse: &Spouse{
+					Incomes: []Income{
+						{Category: "40(1)", Amount: decimal.NewFromInt(1000000)},
+						{Category: "40(2)", Amount: decimal.NewFromInt(200000)},
+					},
+				},
+			},
+			expectHousehold: map[FilingMethod]decimal.Decimal{
+				FilingSeparate:             decimal.NewFromInt(396000),
+				FilingJoint:                decimal.NewFromInt(618000),
+				FilingJointSalarySeparated: decimal.NewFromInt(384000),
+			},
+			expectRecommended: FilingJointSalarySeparated,
+		},
+		{
+			name: "Incomes given differently should only be filed separately",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				Spouse: &Spouse{
+					Incomes: []Income{{Category: "40(1)", Amount: decimal.NewFromInt(500000)}},
+				},
+			},
+			expectHousehold: map[FilingMethod]decimal.Decimal{
+				FilingSeparate: decimal.NewFromInt(48000),
+			},
+			expectRecommended: FilingSeparate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Calculate(defaultTaxBrackets, allowanceDeductions, tc.input)
+			if res.Filing == nil {
+				t.Fatal("Expected a filing comparison")
+			}
+
+			if len(res.Filing.Options) != len(tc.expectHousehold) {
+				t.Fatalf("Expected %d filing options, got %v", len(tc.expectHousehold), res.Filing.Options)
+			}
+
+			for _, option := range res.Filing.Options {
+				expected, ok := tc.expectHousehold[option.Method]
+				if !ok || !option.HouseholdTax.Equal(expected) {
+					t.Errorf("Expected %s household tax %v, got %v", option.Method, expected, option.HouseholdTax)
+				}
+			}
+
+			if res.Filing.Recommended != tc.expectRecommended {
+				t.Errorf("Expected %s to be recommended, got %s", tc.expectRecommended, res.Filing.Recommended)
+			}
+		})
+	}
+}
+
+func TestCalculateWithoutSpouse(t *testing.T) {
+	res := Calculate(defaultTaxBrackets, defaultDeductions, CalculationRequest{TotalIncome: decimal.NewFromInt(500000)})
+	if res.Filing != nil {
+		t.Errorf("Expected no filing comparison, got %v", res.Filing)
+	}
+}
+
+func TestCalculateSpouseAllowance(t *testing.T) {
+	testCases := []struct {
+		name          string
+		spouse        Spouse
+		expectTaxable decimal.Decimal
+	}{
+		{
+			name:          "Spouse without income keeps the spouse allowance",
+			spouse:        Spouse{},
+			expectTaxable: decimal.NewFromInt(380000),
+		},
+		{
+			name:          "Spouse with income drops the spouse allowance",
+			spouse:        Spouse{TotalIncome: decimal.NewFromInt(100000)},
+			expectTaxable: decimal.NewFromInt(440000),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spouse := tc.spouse
+			res := Calculate(defaultTaxBrackets, allowanceDeductions, CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				Allowances:  []Allowance{{AllowanceType: "spouse"}},
+				Spouse:      &spouse,
+			})
+
+			if !res.TaxableIncome.Equal(tc.expectTaxable) {
+				t.Errorf("Expected taxable income %v, got %v", tc.expectTaxable, res.TaxableIncome)
+			}
+		})
+	}
+}
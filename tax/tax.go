@@ -1,109 +1,246 @@
 package tax
 
 import (
-	"math"
-
 	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
 )
 
+// CalculationRequest takes either TotalIncome, from which no expenses are
+// deducted, or Incomes broken down by category. Dividends and
+// ForeignIncomes come on top of either and have their own credits against
+// the tax. Spouse, when given, adds a comparison of the filing methods open
+// to the couple.
 type CalculationRequest struct {
-	TotalIncome float64     `json:"totalIncome" validate:"required,min=0.0"`
-	Wht         float64     `json:"wht" validate:"wht_custom_validation"`
-	Allowances  []Allowance `json:"allowances" validate:"dive"`
+	TotalIncome    decimal.Decimal `json:"totalIncome" validate:"required_without=Incomes,excluded_with=Incomes,min=0.0"`
+	Incomes        []Income        `json:"incomes,omitempty" validate:"dive"`
+	Dividends      []Dividend      `json:"dividends,omitempty" validate:"dive"`
+	DividendOption DividendOption  `json:"dividendOption,omitempty" validate:"omitempty,oneof=credit final"`
+	ForeignIncomes []ForeignIncome `json:"foreignIncomes,omitempty" validate:"dive"`
+	Wht            decimal.Decimal `json:"wht" validate:"wht_custom_validation"`
+	Allowances     []Allowance     `json:"allowances" validate:"dive"`
+	Rounding       Rounding        `json:"rounding,omitempty" validate:"omitempty,oneof=total bracket"`
+	TaxYear        int             `json:"taxYear,omitempty" validate:"omitempty,min=1900,max=2100"`
+	AsOf           Date            `json:"asOf"`
+	Spouse         *Spouse         `json:"spouse,omitempty"`
 }
 
-type Allowance struct {
-	AllowanceType string  `json:"allowanceType" validate:"allowance_type_custom_validation"`
-	Amount        float64 `json:"amount" validate:"min=0.0"`
-}
+// GrossIncome returns TotalIncome, or the sum of Incomes when broken down,
+// plus the foreign incomes and the dividends included in the income.
+func (r CalculationRequest) GrossIncome() decimal.Decimal {
+	gross := r.TotalIncome
+	if len(r.Incomes) > 0 {
+		gross = decimal.Zero
+		for _, income := range r.Incomes {
+			gross = gross.Add(income.Amount)
+		}
+	}
 
-func Calculate(defaultDeductions []db.Deduction, req CalculationRequest) (float64, float64) {
-	var tax float64 = 0
+	for _, income := range r.ForeignIncomes {
+		gross = gross.Add(income.Amount)
+	}
 
-	donationAllowance := calculateDonationAllowance(getDeductionByType(defaultDeductions, "donation").Amount, req.Allowances)
-	kReceiptAllowance := calculateKReceiptAllowance(getDeductionByType(defaultDeductions, "k-receipt").Amount, req.Allowances)
-	taxableIncome := calculateTaxableIncome(req.TotalIncome, getDeductionByType(defaultDeductions, "personal").Amount, donationAllowance, kReceiptAllowance)
+	return gross.Add(r.dividendIncome())
+}
 
-	for _, bracket := range taxBrackets {
-		if taxableIncome <= 0 {
-			break
-		}
+// Year returns the requested tax year, defaulting to the year of AsOf.
+func (r CalculationRequest) Year() int {
+	if r.TaxYear == 0 {
+		return r.AsOfDate().Year()
+	}
 
-		bracketRange := bracket.MaxTotalIncome - bracket.MinTotalIncome
-		incomeInBracket := math.Min(taxableIncome, bracketRange)
-		taxInBracket := incomeInBracket * bracket.TaxRate
-		tax += taxInBracket
-		taxableIncome -= incomeInBracket
+	return r.TaxYear
+}
+
+// AsOfDate returns the date whose deduction settings apply, defaulting to
+// today.
+func (r CalculationRequest) AsOfDate() Date {
+	if r.AsOf.IsZero() {
+		return Today()
 	}
 
-	tax -= req.Wht
+	return r.AsOf
+}
+
+// Allowance claims Amount of an allowance type, or Count dependants for
+// per-person allowances such as spouse, child and parent.
+type Allowance struct {
+	AllowanceType string          `json:"allowanceType" validate:"allowance_type_custom_validation"`
+	Amount        decimal.Decimal `json:"amount" validate:"min=0.0"`
+	Count         int             `json:"count,omitempty" validate:"min=0"`
+}
 
-	if tax < 0 {
-		return 0, formatCalculatedTax(tax * -1)
+// Dependants returns the number of dependants claimed, one when Count is
+// omitted.
+func (a Allowance) Dependants() int {
+	if a.Count == 0 {
+		return 1
 	}
 
-	return formatCalculatedTax(tax), 0
+	return a.Count
+}
+
+// Result is a calculation of one request. Tax and TaxRefund are what is
+// left to pay or due back after WHT.
+type Result struct {
+	Tax             decimal.Decimal
+	TaxRefund       decimal.Decimal
+	TaxLevels       []TaxLevel
+	TaxableIncome   decimal.Decimal
+	TotalAllowances decimal.Decimal
+	// EffectiveRate and EffectiveTaxableRate are the tax before WHT as a
+	// percentage of the gross and of the taxable income.
+	EffectiveRate        decimal.Decimal
+	EffectiveTaxableRate decimal.Decimal
+	// MarginalBracket and MarginalRate are of the bracket the last baht of
+	// taxable income falls into.
+	MarginalBracket string
+	MarginalRate    decimal.Decimal
+	// NetIncome is the gross income less the tax, withheld or still to pay.
+	NetIncome decimal.Decimal
+	// Expenses is set only when the income is broken down by category.
+	Expenses []IncomeExpense
+	// DonationCap is valid only when donations are claimed.
+	DonationCap decimal.NullDecimal
+	// TaxMethods is set only when the minimum tax has to be compared.
+	TaxMethods *TaxMethods
+	// Credits is set only when there are dividends or foreign incomes.
+	Credits *Credits
+	// Filing is set only when the spouse is given.
+	Filing *FilingComparison
+	Trace  Trace
 }
 
 type TaxLevel struct {
-	Level string  `json:"level"`
-	Tax   float64 `json:"tax"`
+	Level string          `json:"level"`
+	Tax   decimal.Decimal `json:"tax"`
 }
 
-func GetTaxLevels(defaultDeductions []db.Deduction, req CalculationRequest) []TaxLevel {
-	var taxLevels []TaxLevel
+// Calculate works out the tax of req in one pass: expenses, allowances,
+// taxable income, the tax of every bracket, the minimum tax when it is
+// larger than the progressive tax, and finally the credits. The spouse
+// allowance is dropped when the spouse has income of their own.
+func Calculate(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, req CalculationRequest) Result {
+	if spouse := req.Spouse; spouse != nil && spouse.GrossIncome().IsPositive() {
+		req = withoutSpouseAllowance(req)
+		req.Spouse = spouse
+	}
 
-	donationAllowance := calculateDonationAllowance(getDeductionByType(defaultDeductions, "donation").Amount, req.Allowances)
-	kReceiptAllowance := calculateKReceiptAllowance(getDeductionByType(defaultDeductions, "k-receipt").Amount, req.Allowances)
-	taxableIncome := calculateTaxableIncome(req.TotalIncome, getDeductionByType(defaultDeductions, "personal").Amount, donationAllowance, kReceiptAllowance)
+	res := Result{
+		Expenses: calculateExpenses(req),
+		Trace: Trace{
+			GrossIncome:       req.GrossIncome(),
+			Expenses:          []IncomeExpense{},
+			PersonalAllowance: getDeductionByType(defaultDeductions, "personal").Amount,
+			Allowances:        []AllowanceStep{},
+			Brackets:          []BracketStep{},
+			WhtCredit:         req.Wht,
+		},
+	}
 
-	for _, bracket := range taxBrackets {
-		bracketRange := bracket.MaxTotalIncome - bracket.MinTotalIncome
-		incomeInBracket := math.Min(taxableIncome, bracketRange)
-		taxInBracket := incomeInBracket * bracket.TaxRate
-		taxLevels = append(taxLevels, TaxLevel{Level: bracket.TaxLevel, Tax: formatCalculatedTax(taxInBracket)})
-		taxableIncome -= incomeInBracket
+	netIncome := res.Trace.GrossIncome
+	for _, expense := range res.Expenses {
+		netIncome = netIncome.Sub(expense.Expense)
+		res.Trace.Expenses = append(res.Trace.Expenses, expense)
 	}
+	res.Trace.NetIncome = netIncome
 
-	return taxLevels
-}
+	res.TotalAllowances = res.Trace.PersonalAllowance
+	allowed, inputs := assessAllowances(defaultDeductions, req, netIncome.Sub(res.TotalAllowances))
+	for _, allowanceType := range allowanceTypes {
+		res.TotalAllowances = res.TotalAllowances.Add(allowed[allowanceType])
 
-func calculateTaxableIncome(totalIncome, personalDeduction, donationAllowance, kReceiptAllowance float64) float64 {
-	return totalIncome - personalDeduction - donationAllowance - kReceiptAllowance
-}
+		in := inputs[allowanceType]
+		if len(in.Claims) == 0 {
+			continue
+		}
 
-func calculateDonationAllowance(maxDonationDeduction float64, allowances []Allowance) float64 {
-	var donationAllowance float64 = 0
-	for _, allowance := range allowances {
-		if allowance.AllowanceType == "donation" {
-			donationAllowance += allowance.Amount
+		step := AllowanceStep{
+			AllowanceType: allowanceType,
+			Claimed:       in.Claimed(),
+			Cap:           roundMoney(allowanceRules[allowanceType].Limit(in)),
+			Allowed:       roundMoney(allowed[allowanceType]),
 		}
+		if in.Deduction.PerPerson {
+			step.Dependants = in.Dependants()
+		}
+		res.Trace.Allowances = append(res.Trace.Allowances, step)
 	}
+	res.TotalAllowances = roundMoney(res.TotalAllowances)
+	taxableIncome := decimal.Max(netIncome.Sub(res.TotalAllowances), decimal.Zero)
+	res.TaxableIncome = taxableIncome
+	res.Trace.TaxableIncome = taxableIncome
+	res.DonationCap = donationCap(inputs)
+
+	progressiveTax := decimal.Zero
+	for i, income := range calculateBracketIncomes(taxBrackets, taxableIncome) {
+		bracket := taxBrackets[i]
+		bracketTax := taxInBracket(bracket, income)
+		if income.IsPositive() || i == 0 {
+			res.MarginalBracket = LevelLabel(bracket)
+			res.MarginalRate = bracket.TaxRate
+		}
 
-	if donationAllowance > maxDonationDeduction {
-		return maxDonationDeduction
+		res.TaxLevels = append(res.TaxLevels, TaxLevel{Level: LevelLabel(bracket), Tax: roundMoney(bracketTax)})
+		res.Trace.Brackets = append(res.Trace.Brackets, BracketStep{
+			Level:   LevelLabel(bracket),
+			TaxRate: bracket.TaxRate,
+			Income:  income,
+			Tax:     roundMoney(bracketTax),
+		})
+
+		if req.Rounding == RoundingBracket {
+			bracketTax = roundMoney(bracketTax)
+		}
+		progressiveTax = progressiveTax.Add(bracketTax)
 	}
+	progressiveTax = roundMoney(progressiveTax)
+	res.Trace.ProgressiveTax = progressiveTax
+
+	tax := progressiveTax
+	if methods, ok := compareTaxMethods(progressiveTax, req); ok {
+		res.TaxMethods = &methods
+		res.Trace.TaxMethods = res.TaxMethods
+		if methods.Method == MethodMinimum {
+			tax = methods.MinimumTax
+		}
+	}
+	res.Trace.Tax = tax
 
-	return donationAllowance
-}
+	res.EffectiveRate = percentOf(tax, res.Trace.GrossIncome)
+	res.EffectiveTaxableRate = percentOf(tax, taxableIncome)
+	res.NetIncome = roundMoney(res.Trace.GrossIncome.Sub(tax))
 
-func calculateKReceiptAllowance(maxKReceiptDeduction float64, allowances []Allowance) float64 {
-	var kReceiptAllowance float64 = 0
-	for _, allowance := range allowances {
-		if allowance.AllowanceType == "k-receipt" {
-			kReceiptAllowance += allowance.Amount
-		}
+	credits := calculateCredits(req, tax, res.Expenses, res.Trace.NetIncome)
+	if req.hasCredits() {
+		res.Credits = &credits
+		res.Trace.Credits = &credits
 	}
 
-	if kReceiptAllowance > maxKReceiptDeduction {
-		return maxKReceiptDeduction
+	tax = tax.Sub(credits.Total)
+	if tax.IsNegative() {
+		res.Tax, res.TaxRefund = decimal.Zero, roundMoney(tax.Neg())
+	} else {
+		res.Tax, res.TaxRefund = roundMoney(tax), decimal.Zero
 	}
+	res.Trace.TaxPayable = res.Tax
+	res.Trace.TaxRefund = res.TaxRefund
 
-	return kReceiptAllowance
+	if req.Spouse != nil {
+		filing := compareFilings(taxBrackets, defaultDeductions, req)
+		res.Filing = &filing
+	}
+
+	return res
 }
 
-func formatCalculatedTax(tax float64) float64 {
-	return math.Round(tax*100) / 100
+// percentOf returns part as a percentage of whole, or zero when whole is
+// not positive.
+func percentOf(part, whole decimal.Decimal) decimal.Decimal {
+	if !whole.IsPositive() {
+		return decimal.Zero
+	}
+
+	return roundMoney(part.Shift(2).Div(whole))
 }
 
 func getDeductionByType(deductions []db.Deduction, deductionType string) db.Deduction {
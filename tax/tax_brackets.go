@@ -1,18 +1,125 @@
 package tax
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"strings"
 
-type TaxBracket struct {
-	MinTotalIncome float64
-	MaxTotalIncome float64
-	TaxRate        float64
-	TaxLevel       string
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+var maxTaxRate = decimal.NewFromInt(100)
+
+// ValidateTaxBrackets checks that brackets form one progressive schedule:
+// starting at zero, ordered, without gaps or overlaps, with only the last
+// bracket left unbounded and every rate within 0-100%.
+func ValidateTaxBrackets(brackets []db.TaxBracket) error {
+	if len(brackets) == 0 {
+		return errors.New("tax brackets must not be empty")
+	}
+
+	if !brackets[0].MinTotalIncome.IsZero() {
+		return errors.New("first tax bracket must start at 0")
+	}
+
+	for i, bracket := range brackets {
+		if bracket.TaxRate.IsNegative() || bracket.TaxRate.GreaterThan(maxTaxRate) {
+			return fmt.Errorf("tax bracket %d: tax rate must be between 0 and 100", i+1)
+		}
+
+		isLast := i == len(brackets)-1
+		if !bracket.MaxTotalIncome.Valid {
+			if !isLast {
+				return fmt.Errorf("tax bracket %d: only the last tax bracket can be unbounded", i+1)
+			}
+			continue
+		}
+
+		if !bracket.MaxTotalIncome.Decimal.GreaterThan(bracket.MinTotalIncome) {
+			return fmt.Errorf("tax bracket %d: max total income must be greater than min total income", i+1)
+		}
+
+		if isLast {
+			return fmt.Errorf("tax bracket %d: last tax bracket must be unbounded", i+1)
+		}
+
+		next := brackets[i+1].MinTotalIncome
+		if next.LessThan(bracket.MaxTotalIncome.Decimal) {
+			return fmt.Errorf("tax bracket %d overlaps tax bracket %d", i+2, i+1)
+		}
+		if next.GreaterThan(bracket.MaxTotalIncome.Decimal) {
+			return fmt.Errorf("gap between tax bracket %d and tax bracket %d", i+1, i+2)
+		}
+	}
+
+	return nil
+}
+
+// LevelLabel renders the income range of bracket, e.g. "150,001-500,000"
+// or "2,000,001 ขึ้นไป" for the unbounded bracket.
+func LevelLabel(bracket db.TaxBracket) string {
+	from := "0"
+	if !bracket.MinTotalIncome.IsZero() {
+		from = formatBaht(bracket.MinTotalIncome.Add(decimal.NewFromInt(1)))
+	}
+
+	if !bracket.MaxTotalIncome.Valid {
+		return from + " ขึ้นไป"
+	}
+
+	return from + "-" + formatBaht(bracket.MaxTotalIncome.Decimal)
+}
+
+// formatBaht groups the integer part with commas and keeps satang only
+// when there are any.
+func formatBaht(amount decimal.Decimal) string {
+	text := amount.StringFixed(2)
+	if amount.Equal(amount.Truncate(0)) {
+		text = amount.StringFixed(0)
+	}
+
+	integer, fraction, _ := strings.Cut(text, ".")
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if fraction != "" {
+		return grouped.String() + "." + fraction
+	}
+
+	return grouped.String()
+}
+
+// calculateBracketIncomes splits taxableIncome into the part that falls
+// into each bracket in taxBrackets order.
+func calculateBracketIncomes(taxBrackets []db.TaxBracket, taxableIncome decimal.Decimal) []decimal.Decimal {
+	incomes := make([]decimal.Decimal, 0, len(taxBrackets))
+	for _, bracket := range taxBrackets {
+		income := decimal.Max(incomeInBracket(bracket, taxableIncome), decimal.Zero)
+		incomes = append(incomes, income)
+		taxableIncome = taxableIncome.Sub(income)
+	}
+
+	return incomes
+}
+
+// incomeInBracket returns the part of taxableIncome that falls into the
+// bracket, assuming the lower brackets have already been filled.
+func incomeInBracket(bracket db.TaxBracket, taxableIncome decimal.Decimal) decimal.Decimal {
+	if !bracket.MaxTotalIncome.Valid {
+		return taxableIncome
+	}
+
+	bracketRange := bracket.MaxTotalIncome.Decimal.Sub(bracket.MinTotalIncome)
+	return decimal.Min(taxableIncome, bracketRange)
 }
 
-var taxBrackets = []TaxBracket{
-	{MinTotalIncome: 0, MaxTotalIncome: 150000, TaxRate: 0, TaxLevel: "0-150,000"},
-	{MinTotalIncome: 150000, MaxTotalIncome: 500000, TaxRate: 0.1, TaxLevel: "150,001-500,000"},
-	{MinTotalIncome: 500000, MaxTotalIncome: 1000000, TaxRate: 0.15, TaxLevel: "500,001-1,000,000"},
-	{MinTotalIncome: 1000000, MaxTotalIncome: 2000000, TaxRate: 0.2, TaxLevel: "1,000,001-2,000,000"},
-	{MinTotalIncome: 2000000, MaxTotalIncome: math.MaxFloat64, TaxRate: 0.35, TaxLevel: "2,000,001 ขึ้นไป"},
+// taxInBracket applies the bracket's percentage rate to income.
+func taxInBracket(bracket db.TaxBracket, income decimal.Decimal) decimal.Decimal {
+	return income.Mul(bracket.TaxRate).Shift(-2)
 }
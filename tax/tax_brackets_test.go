@@ -0,0 +1,123 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+func bracket(min int64, max int64, rate int64) db.TaxBracket {
+	b := db.TaxBracket{MinTotalIncome: decimal.NewFromInt(min), TaxRate: decimal.NewFromInt(rate)}
+	if max >= 0 {
+		b.MaxTotalIncome = decimal.NewNullDecimal(decimal.NewFromInt(max))
+	}
+
+	return b
+}
+
+func TestValidateTaxBrackets(t *testing.T) {
+	testCases := []struct {
+		name      string
+		brackets  []db.TaxBracket
+		expectErr bool
+	}{
+		{
+			name:     "Default brackets should be valid",
+			brackets: defaultTaxBrackets,
+		},
+		{
+			name:     "Single unbounded bracket should be valid",
+			brackets: []db.TaxBracket{bracket(0, -1, 10)},
+		},
+		{
+			name:      "Empty brackets should be invalid",
+			brackets:  []db.TaxBracket{},
+			expectErr: true,
+		},
+		{
+			name:      "First bracket not starting at 0 should be invalid",
+			brackets:  []db.TaxBracket{bracket(100, 500, 0), bracket(500, -1, 10)},
+			expectErr: true,
+		},
+		{
+			name:      "Gap between brackets should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, 150000, 0), bracket(160000, -1, 10)},
+			expectErr: true,
+		},
+		{
+			name:      "Overlapping brackets should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, 150000, 0), bracket(140000, -1, 10)},
+			expectErr: true,
+		},
+		{
+			name:      "Max not greater than min should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, 0, 0), bracket(0, -1, 10)},
+			expectErr: true,
+		},
+		{
+			name:      "Unbounded bracket before the last should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, -1, 0), bracket(150000, -1, 10)},
+			expectErr: true,
+		},
+		{
+			name:      "Bounded last bracket should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, 150000, 0), bracket(150000, 500000, 10)},
+			expectErr: true,
+		},
+		{
+			name:      "Negative rate should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, -1, -1)},
+			expectErr: true,
+		},
+		{
+			name:      "Rate over 100 should be invalid",
+			brackets:  []db.TaxBracket{bracket(0, -1, 101)},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTaxBrackets(tc.brackets)
+
+			if tc.expectErr && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+
+			if !tc.expectErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLevelLabel(t *testing.T) {
+	testCases := []struct {
+		name    string
+		bracket db.TaxBracket
+		expect  string
+	}{
+		{name: "First bracket", bracket: bracket(0, 150000, 0), expect: "0-150,000"},
+		{name: "Middle bracket", bracket: bracket(1000000, 2000000, 20), expect: "1,000,001-2,000,000"},
+		{name: "Unbounded bracket", bracket: bracket(2000000, -1, 35), expect: "2,000,001 ขึ้นไป"},
+		{
+			name: "Bracket with satang",
+			bracket: db.TaxBracket{
+				MinTotalIncome: decimal.RequireFromString("999.5"),
+				MaxTotalIncome: decimal.NewNullDecimal(decimal.RequireFromString("1500.25")),
+			},
+			expect: "1,000.50-1,500.25",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := LevelLabel(tc.bracket)
+
+			if got != tc.expect {
+				t.Errorf("Expected %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
@@ -1,23 +1,33 @@
 package tax
 
 import (
-	"reflect"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
 )
 
 type testCase struct {
 	name         string
 	input        CalculationRequest
-	expectTax    float64
-	expectRefund float64
+	expectTax    decimal.Decimal
+	expectRefund decimal.Decimal
+}
+
+var defaultTaxBrackets = []db.TaxBracket{
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(0), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(150000)), TaxRate: decimal.NewFromInt(0)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(150000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(500000)), TaxRate: decimal.NewFromInt(10)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(500000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(1000000)), TaxRate: decimal.NewFromInt(15)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(1000000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(2000000)), TaxRate: decimal.NewFromInt(20)},
+	{TaxYear: 2017, MinTotalIncome: decimal.NewFromInt(2000000), TaxRate: decimal.NewFromInt(35)},
 }
 
 var defaultDeductions = []db.Deduction{
-	{Type: "personal", Amount: 60000.0},
-	{Type: "donation", Amount: 100000.0},
-	{Type: "k-receipt", Amount: 50000.0},
+	{Type: "personal", Amount: decimal.NewFromFloat(60000.0)},
+	{Type: "donation", Amount: decimal.NewFromFloat(100000.0)},
+	{Type: "k-receipt", Amount: decimal.NewFromFloat(50000.0)},
 }
 
 func TestCalculateTaxWithTotalIncomeOnly(t *testing.T) {
@@ -25,117 +35,118 @@ func TestCalculateTaxWithTotalIncomeOnly(t *testing.T) {
 		{
 			name: "Total income 0, should return 0",
 			input: CalculationRequest{
-				TotalIncome: 0.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(0.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 		{
 			name: "Total income 30,000.0, should return 0",
 			input: CalculationRequest{
-				TotalIncome: 30000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(30000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 		{
 			name: "Total income 150,000.0, should return 0",
 			input: CalculationRequest{
-				TotalIncome: 150000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(150000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 		{
 			name: "Total income 150,001.0, should return 0",
 			input: CalculationRequest{
-				TotalIncome: 150001.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(150001.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 		{
 			name: "Total income 500,000 should return 29,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 0.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(0.00)},
 				},
 			},
-			expectTax: 29000.0,
+			expectTax: decimal.NewFromFloat(29000.0),
 		},
 		{
 			name: "Total income 500,001 should return 29,000.1",
 			input: CalculationRequest{
-				TotalIncome: 500001.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500001.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 0.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(0.00)},
 				},
 			},
-			expectTax: 29000.1,
+			expectTax: decimal.NewFromFloat(29000.1),
 		},
 		{
 			name: "Total income 1,000,000 should return 101,000",
 			input: CalculationRequest{
-				TotalIncome: 1000000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(1000000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 101000.0,
+			expectTax: decimal.NewFromFloat(101000.0),
 		},
 		{
 			name: "Total income 1,000,001 should return 101,000.15",
 			input: CalculationRequest{
-				TotalIncome: 1000001.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(1000001.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 101000.15,
+			expectTax: decimal.NewFromFloat(101000.15),
 		},
 		{
 			name: "Total income 2,000,000 should return 298,000",
 			input: CalculationRequest{
-				TotalIncome: 2000000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(2000000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 298000.0,
+			expectTax: decimal.NewFromFloat(298000.0),
 		},
 		{
 			name: "Total income 2,000,001 should return 298,000.2",
 			input: CalculationRequest{
-				TotalIncome: 2000001.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(2000001.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 298000.2,
+			expectTax: decimal.NewFromFloat(298000.2),
 		},
 		{
 			name: "Total income 4,000,000 should return 989,000",
 			input: CalculationRequest{
-				TotalIncome: 4000000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(4000000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 989000.0,
+			expectTax: decimal.NewFromFloat(989000.0),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tax, refund := Calculate(defaultDeductions, tc.input)
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+			tax, refund := res.Tax, res.TaxRefund
 
-			if tax != tc.expectTax {
+			if !tax.Equal(tc.expectTax) {
 				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
 			}
 
-			if refund != 0 {
+			if !refund.IsZero() {
 				t.Errorf("Expected 0, got %v", refund)
 			}
 		})
@@ -147,56 +158,57 @@ func TestCalculateTaxWithWht(t *testing.T) {
 		{
 			name: "Total income 0 and WHT 0 should return 0",
 			input: CalculationRequest{
-				TotalIncome: 0.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(0.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 		{
 			name: "Total income 500,000.0 and WHT 0.0 should return 29,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 0.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(0.00)},
 				},
 			},
-			expectTax: 29000.0,
+			expectTax: decimal.NewFromFloat(29000.0),
 		},
 		{
 			name: "Total income 500,000.0 and WHT 25,000.0 should return 4,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         25000.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(25000.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 0.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(0.00)},
 				},
 			},
-			expectTax: 4000.0,
+			expectTax: decimal.NewFromFloat(4000.0),
 		},
 		{
 			name: "Total income 500,000.0 and WHT 29,000.0 should return 0",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         29000.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(29000.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 0.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(0.00)},
 				},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tax, refund := Calculate(defaultDeductions, tc.input)
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+			tax, refund := res.Tax, res.TaxRefund
 
-			if tax != tc.expectTax {
+			if !tax.Equal(tc.expectTax) {
 				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
 			}
 
-			if refund != 0 {
+			if !refund.IsZero() {
 				t.Errorf("Expected 0, got %v", refund)
 			}
 		})
@@ -208,46 +220,47 @@ func TestCalculateTaxWithDonationAllowances(t *testing.T) {
 		{
 			name: "Total income 0, WHT 0.0 and no allowances, should return 0",
 			input: CalculationRequest{
-				TotalIncome: 0.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(0.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
-			expectTax: 0.0,
+			expectTax: decimal.NewFromFloat(0.0),
 		},
 		{
 			name: "Total income 500,000.0 and WHT 0.0 and donation allowance 30,000 should return 39,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 10000.00},
-					{AllowanceType: "donation", Amount: 20000.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(10000.00)},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(20000.00)},
 				},
 			},
-			expectTax: 26000.0,
+			expectTax: decimal.NewFromFloat(26000.0),
 		},
 		{
 			name: "Total income 500,000.0 and WHT 0.0 and donation allowance 200,000 should return 19,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 200000.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(200000.00)},
 				},
 			},
-			expectTax: 19000.0,
+			expectTax: decimal.NewFromFloat(19000.0),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tax, refund := Calculate(defaultDeductions, tc.input)
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+			tax, refund := res.Tax, res.TaxRefund
 
-			if tax != tc.expectTax {
+			if !tax.Equal(tc.expectTax) {
 				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
 			}
 
-			if refund != 0 {
+			if !refund.IsZero() {
 				t.Errorf("Expected 0, got %v", refund)
 			}
 		})
@@ -259,32 +272,91 @@ func TestCalculateTaxWithKReceiptAllowances(t *testing.T) {
 		{
 			name: "Total income 500,000 WHT 0.0 k-receipt 200,000 and donation 100,000 should return 14,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "k-receipt", Amount: 200000.00},
-					{AllowanceType: "donation", Amount: 100000.00},
+					{AllowanceType: "k-receipt", Amount: decimal.NewFromFloat(200000.00)},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(100000.00)},
 				},
 			},
-			expectTax: 14000.0,
+			expectTax: decimal.NewFromFloat(14000.0),
 		},
 		{
 			name: "Total income 500,000 WHT 0.0 k-receipt 30,000 should return 26,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "k-receipt", Amount: 30000.00},
+					{AllowanceType: "k-receipt", Amount: decimal.NewFromFloat(30000.00)},
 				},
 			},
-			expectTax: 26000.0,
+			expectTax: decimal.NewFromFloat(26000.0),
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tax, _ := Calculate(defaultDeductions, tc.input)
+			tax := Calculate(defaultTaxBrackets, defaultDeductions, tc.input).Tax
 
-			if tax != tc.expectTax {
+			if !tax.Equal(tc.expectTax) {
+				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
+			}
+		})
+	}
+}
+
+var allowanceDeductions = append([]db.Deduction{
+	{Type: "spouse", Amount: decimal.NewFromInt(60000), PerPerson: true, MaxCount: 1},
+	{Type: "child", Amount: decimal.NewFromInt(30000), PerPerson: true},
+	{Type: "child-2018", Amount: decimal.NewFromInt(60000), PerPerson: true},
+	{Type: "parent", Amount: decimal.NewFromInt(30000), PerPerson: true, MaxCount: 4},
+	{Type: "life-insurance", Amount: decimal.NewFromInt(100000)},
+	{Type: "provident-fund", Amount: decimal.NewFromInt(500000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(15))},
+	{Type: "ssf", Amount: decimal.NewFromInt(200000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(30))},
+}, defaultDeductions...)
+
+func TestCalculateTaxWithAllowanceTypes(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "Total income 1,000,000 spouse, 2 children, 1 child since 2018 and 5 parents should return 56,000",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(1000000),
+				Allowances: []Allowance{
+					{AllowanceType: "spouse"},
+					{AllowanceType: "child", Count: 2},
+					{AllowanceType: "child-2018", Count: 1},
+					{AllowanceType: "parent", Count: 5},
+				},
+			},
+			expectTax: decimal.NewFromInt(56000),
+		},
+		{
+			name: "Total income 600,000 ssf 300,000 provident fund 100,000 life insurance 150,000 should return 2,000",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(600000),
+				Allowances: []Allowance{
+					{AllowanceType: "ssf", Amount: decimal.NewFromInt(300000)},
+					{AllowanceType: "provident-fund", Amount: decimal.NewFromInt(100000)},
+					{AllowanceType: "life-insurance", Amount: decimal.NewFromInt(150000)},
+				},
+			},
+			expectTax: decimal.NewFromInt(2000),
+		},
+		{
+			name: "Allowance types without a deduction should not be deducted",
+			input: CalculationRequest{
+				TotalIncome: decimal.NewFromInt(500000),
+				Allowances: []Allowance{
+					{AllowanceType: "e-receipt", Amount: decimal.NewFromInt(50000)},
+				},
+			},
+			expectTax: decimal.NewFromInt(29000),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tax := Calculate(defaultTaxBrackets, allowanceDeductions, tc.input).Tax
+
+			if !tax.Equal(tc.expectTax) {
 				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
 			}
 		})
@@ -296,45 +368,164 @@ func TestCalculateTaxAndRefund(t *testing.T) {
 		{
 			name: "Total income 500,000.0 and WHT 100,000.0 and donation allowance 200,000 should return refund 81,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         100000.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(100000.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 200000.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(200000.00)},
 				},
 			},
-			expectTax:    0.0,
-			expectRefund: 81000.0,
+			expectTax:    decimal.NewFromFloat(0.0),
+			expectRefund: decimal.NewFromFloat(81000.0),
 		},
 		{
 			name: "Total income 1,000,000.0 and WHT 200,000.0 and donation allowance 150,000 should return refund 114,000",
 			input: CalculationRequest{
-				TotalIncome: 1000000.0,
-				Wht:         200000.0,
+				TotalIncome: decimal.NewFromFloat(1000000.0),
+				Wht:         decimal.NewFromFloat(200000.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 150000.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(150000.00)},
 				},
 			},
-			expectTax:    0.0,
-			expectRefund: 114000.0,
+			expectTax:    decimal.NewFromFloat(0.0),
+			expectRefund: decimal.NewFromFloat(114000.0),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tax, refund := Calculate(defaultDeductions, tc.input)
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+			tax, refund := res.Tax, res.TaxRefund
 
-			if tax != tc.expectTax {
+			if !tax.Equal(tc.expectTax) {
 				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
 			}
 
-			if refund != tc.expectRefund {
+			if !refund.Equal(tc.expectRefund) {
 				t.Errorf("Expected %v, got %v", tc.expectRefund, refund)
 			}
 		})
 	}
 }
 
-func TestGetTaxLevels(t *testing.T) {
+func TestCalculateTaxRounding(t *testing.T) {
+	testCases := []testCase{
+		{
+			name: "Total income 560,000.10 should round half-up to 35,000.02",
+			input: CalculationRequest{
+				TotalIncome: decimal.RequireFromString("560000.10"),
+				Wht:         decimal.Zero,
+				Allowances:  []Allowance{},
+			},
+			expectTax: decimal.RequireFromString("35000.02"),
+		},
+		{
+			name: "Total income 560,000.10 rounded per bracket should return 35,000.02",
+			input: CalculationRequest{
+				TotalIncome: decimal.RequireFromString("560000.10"),
+				Wht:         decimal.Zero,
+				Allowances:  []Allowance{},
+				Rounding:    RoundingBracket,
+			},
+			expectTax: decimal.RequireFromString("35000.02"),
+		},
+		{
+			name: "Total income 4,000,000.03 and WHT 0.004 should return 989,000.01",
+			input: CalculationRequest{
+				TotalIncome: decimal.RequireFromString("4000000.03"),
+				Wht:         decimal.RequireFromString("0.004"),
+				Allowances:  []Allowance{},
+			},
+			expectTax: decimal.RequireFromString("989000.01"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Calculate(defaultTaxBrackets, defaultDeductions, tc.input)
+			tax, refund := res.Tax, res.TaxRefund
+
+			if !tax.Equal(tc.expectTax) {
+				t.Errorf("Expected %v, got %v", tc.expectTax, tax)
+			}
+
+			if !refund.IsZero() {
+				t.Errorf("Expected 0, got %v", refund)
+			}
+		})
+	}
+}
+
+func TestCalculateTaxWithTaxBrackets(t *testing.T) {
+	taxBrackets := []db.TaxBracket{
+		{TaxYear: 2016, MinTotalIncome: decimal.NewFromInt(0), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(150000)), TaxRate: decimal.NewFromInt(0)},
+		{TaxYear: 2016, MinTotalIncome: decimal.NewFromInt(150000), MaxTotalIncome: decimal.NewNullDecimal(decimal.NewFromInt(300000)), TaxRate: decimal.NewFromInt(5)},
+		{TaxYear: 2016, MinTotalIncome: decimal.NewFromInt(300000), TaxRate: decimal.NewFromInt(10)},
+	}
+
+	res := Calculate(taxBrackets, defaultDeductions, CalculationRequest{
+		TotalIncome: decimal.NewFromInt(500000),
+		Wht:         decimal.Zero,
+		Allowances:  []Allowance{},
+	})
+	tax, refund := res.Tax, res.TaxRefund
+
+	if !tax.Equal(decimal.NewFromInt(21500)) {
+		t.Errorf("Expected 21500, got %v", tax)
+	}
+
+	if !refund.IsZero() {
+		t.Errorf("Expected 0, got %v", refund)
+	}
+
+	levels := res.TaxLevels
+	if len(levels) != 3 || levels[2].Level != "300,001 ขึ้นไป" || !levels[2].Tax.Equal(decimal.NewFromInt(14000)) {
+		t.Errorf("Expected 3 levels with 14000 in the last one, got %v", levels)
+	}
+}
+
+func TestCalculationRequestYear(t *testing.T) {
+	if got := (CalculationRequest{TaxYear: 2020}).Year(); got != 2020 {
+		t.Errorf("Expected 2020, got %v", got)
+	}
+
+	if got := (CalculationRequest{}).Year(); got != time.Now().Year() {
+		t.Errorf("Expected %v, got %v", time.Now().Year(), got)
+	}
+
+	asOf := NewDate(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	if got := (CalculationRequest{AsOf: asOf}).Year(); got != 2019 {
+		t.Errorf("Expected 2019, got %v", got)
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	var req CalculationRequest
+	if err := json.Unmarshal([]byte(`{"totalIncome":0,"asOf":"2019-06-01"}`), &req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := req.AsOfDate().String(); got != "2019-06-01" {
+		t.Errorf("Expected 2019-06-01, got %v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"asOf":"01/06/2019"}`), &req); err == nil {
+		t.Errorf("Expected an error for a malformed date")
+	}
+
+	data, err := json.Marshal(struct {
+		Set   Date `json:"set"`
+		Unset Date `json:"unset"`
+	}{Set: req.AsOf})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := string(data); got != `{"set":"2019-06-01","unset":null}` {
+		t.Errorf("Unexpected JSON %v", got)
+	}
+}
+
+func TestCalculateTaxLevels(t *testing.T) {
 	testCases := []struct {
 		name   string
 		input  CalculationRequest
@@ -343,44 +534,98 @@ func TestGetTaxLevels(t *testing.T) {
 		{
 			name: "Total income 0, should return 0 of all levels",
 			input: CalculationRequest{
-				TotalIncome: 0.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(0.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances:  []Allowance{},
 			},
 			expect: []TaxLevel{
-				{Level: "0-150,000", Tax: 0.0},
-				{Level: "150,001-500,000", Tax: 0.0},
-				{Level: "500,001-1,000,000", Tax: 0.0},
-				{Level: "1,000,001-2,000,000", Tax: 0.0},
-				{Level: "2,000,001 ขึ้นไป", Tax: 0.0},
+				{Level: "0-150,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "150,001-500,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "500,001-1,000,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "1,000,001-2,000,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "2,000,001 ขึ้นไป", Tax: decimal.NewFromFloat(0.0)},
 			},
 		},
 		{
 			name: "Total income 500,000 and donation allowance 200,000, should return 19,000 in level 150,000-500,000",
 			input: CalculationRequest{
-				TotalIncome: 500000.0,
-				Wht:         0.0,
+				TotalIncome: decimal.NewFromFloat(500000.0),
+				Wht:         decimal.NewFromFloat(0.0),
 				Allowances: []Allowance{
-					{AllowanceType: "donation", Amount: 200000.00},
+					{AllowanceType: "donation", Amount: decimal.NewFromFloat(200000.00)},
 				},
 			},
 			expect: []TaxLevel{
-				{Level: "0-150,000", Tax: 0.0},
-				{Level: "150,001-500,000", Tax: 19000.0},
-				{Level: "500,001-1,000,000", Tax: 0.0},
-				{Level: "1,000,001-2,000,000", Tax: 0.0},
-				{Level: "2,000,001 ขึ้นไป", Tax: 0.0},
+				{Level: "0-150,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "150,001-500,000", Tax: decimal.NewFromFloat(19000.0)},
+				{Level: "500,001-1,000,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "1,000,001-2,000,000", Tax: decimal.NewFromFloat(0.0)},
+				{Level: "2,000,001 ขึ้นไป", Tax: decimal.NewFromFloat(0.0)},
 			},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := GetTaxLevels(defaultDeductions, tc.input)
+			got := Calculate(defaultTaxBrackets, defaultDeductions, tc.input).TaxLevels
+
+			if len(got) != len(tc.expect) {
+				t.Fatalf("Expected %v, got %v", tc.expect, got)
+			}
 
-			if !reflect.DeepEqual(got, tc.expect) {
-				t.Errorf("Expected %v, got %v", tc.expect, got)
+			for i := range got {
+				if got[i].Level != tc.expect[i].Level || !got[i].Tax.Equal(tc.expect[i].Tax) {
+					t.Errorf("Expected %v, got %v", tc.expect, got)
+				}
 			}
 		})
 	}
 }
+
+func TestCalculateResult(t *testing.T) {
+	res := Calculate(defaultTaxBrackets, defaultDeductions, CalculationRequest{
+		TotalIncome: decimal.NewFromInt(500000),
+		Wht:         decimal.NewFromInt(25000),
+		Allowances: []Allowance{
+			{AllowanceType: "donation", Amount: decimal.NewFromInt(200000)},
+		},
+	})
+
+	if !res.Tax.IsZero() || !res.TaxRefund.Equal(decimal.NewFromInt(6000)) {
+		t.Errorf("Expected 6000 refund, got %v tax and %v refund", res.Tax, res.TaxRefund)
+	}
+
+	if len(res.TaxLevels) != len(defaultTaxBrackets) || !res.TaxLevels[1].Tax.Equal(decimal.NewFromInt(19000)) {
+		t.Errorf("Expected the tax levels of a refund, got %v", res.TaxLevels)
+	}
+
+	if !res.TaxableIncome.Equal(decimal.NewFromInt(340000)) {
+		t.Errorf("Expected taxable income 340000, got %v", res.TaxableIncome)
+	}
+
+	if !res.EffectiveRate.Equal(decimal.NewFromFloat(3.8)) {
+		t.Errorf("Expected effective rate 3.8, got %v", res.EffectiveRate)
+	}
+
+	if !res.MarginalRate.Equal(decimal.NewFromInt(10)) || res.MarginalBracket != "150,001-500,000" {
+		t.Errorf("Expected marginal rate 10 of 150,001-500,000, got %v of %v", res.MarginalRate, res.MarginalBracket)
+	}
+
+	if !res.EffectiveTaxableRate.Equal(decimal.NewFromFloat(5.59)) {
+		t.Errorf("Expected effective taxable rate 5.59, got %v", res.EffectiveTaxableRate)
+	}
+
+	if !res.TotalAllowances.Equal(decimal.NewFromInt(160000)) {
+		t.Errorf("Expected total allowances 160000, got %v", res.TotalAllowances)
+	}
+
+	if !res.NetIncome.Equal(decimal.NewFromInt(481000)) {
+		t.Errorf("Expected net income 481000, got %v", res.NetIncome)
+	}
+
+	res = Calculate(defaultTaxBrackets, defaultDeductions, CalculationRequest{TotalIncome: decimal.Zero})
+	if !res.EffectiveRate.IsZero() || !res.EffectiveTaxableRate.IsZero() || !res.MarginalRate.IsZero() ||
+		res.MarginalBracket != "0-150,000" || !res.TaxableIncome.IsZero() || !res.NetIncome.IsZero() {
+		t.Errorf("Unexpected result of no income %+v", res)
+	}
+}
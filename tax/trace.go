@@ -0,0 +1,41 @@
+package tax
+
+import "github.com/shopspring/decimal"
+
+// Trace explains a calculation step by step, from the gross income to the
+// tax to pay or the refund due.
+type Trace struct {
+	GrossIncome       decimal.Decimal `json:"grossIncome"`
+	Expenses          []IncomeExpense `json:"expenses"`
+	NetIncome         decimal.Decimal `json:"netIncome"`
+	PersonalAllowance decimal.Decimal `json:"personalAllowance"`
+	Allowances        []AllowanceStep `json:"allowances"`
+	TaxableIncome     decimal.Decimal `json:"taxableIncome"`
+	Brackets          []BracketStep   `json:"brackets"`
+	ProgressiveTax    decimal.Decimal `json:"progressiveTax"`
+	TaxMethods        *TaxMethods     `json:"taxMethods,omitempty"`
+	Tax               decimal.Decimal `json:"tax"`
+	WhtCredit         decimal.Decimal `json:"whtCredit"`
+	Credits           *Credits        `json:"credits,omitempty"`
+	TaxPayable        decimal.Decimal `json:"taxPayable"`
+	TaxRefund         decimal.Decimal `json:"taxRefund"`
+}
+
+// AllowanceStep is one claimed allowance type: the amount or number of
+// dependants claimed, the cap it was held to and the amount allowed.
+type AllowanceStep struct {
+	AllowanceType string          `json:"allowanceType"`
+	Claimed       decimal.Decimal `json:"claimed"`
+	Dependants    int             `json:"dependants,omitempty"`
+	Cap           decimal.Decimal `json:"cap"`
+	Allowed       decimal.Decimal `json:"allowed"`
+}
+
+// BracketStep is the part of the taxable income that falls into one
+// bracket and the tax on it.
+type BracketStep struct {
+	Level   string          `json:"level"`
+	TaxRate decimal.Decimal `json:"taxRate"`
+	Income  decimal.Decimal `json:"income"`
+	Tax     decimal.Decimal `json:"tax"`
+}
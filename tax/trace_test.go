@@ -0,0 +1,79 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateTrace(t *testing.T) {
+	deductions := []db.Deduction{
+		{Type: "personal", Amount: decimal.NewFromInt(60000)},
+		{Type: "k-receipt", Amount: decimal.NewFromInt(50000)},
+		{Type: "child", Amount: decimal.NewFromInt(30000), PerPerson: true},
+		{Type: "donation", Amount: decimal.NewFromInt(100000), IncomePercent: decimal.NewNullDecimal(decimal.NewFromInt(10))},
+	}
+
+	req := CalculationRequest{
+		TotalIncome: decimal.NewFromInt(530000),
+		Wht:         decimal.NewFromInt(25000),
+		Allowances: []Allowance{
+			{AllowanceType: "k-receipt", Amount: decimal.NewFromInt(200000)},
+			{AllowanceType: "child", Count: 1},
+			{AllowanceType: "donation", Amount: decimal.NewFromInt(100000)},
+		},
+	}
+
+	trace := Calculate(defaultTaxBrackets, deductions, req).Trace
+
+	expectAllowances := []AllowanceStep{
+		{AllowanceType: "k-receipt", Claimed: decimal.NewFromInt(200000), Cap: decimal.NewFromInt(50000), Allowed: decimal.NewFromInt(50000)},
+		{AllowanceType: "child", Claimed: decimal.Zero, Dependants: 1, Cap: decimal.NewFromInt(30000), Allowed: decimal.NewFromInt(30000)},
+		{AllowanceType: "donation", Claimed: decimal.NewFromInt(100000), Cap: decimal.NewFromInt(39000), Allowed: decimal.NewFromInt(39000)},
+	}
+	if len(trace.Allowances) != len(expectAllowances) {
+		t.Fatalf("Expected %d allowances, got %d", len(expectAllowances), len(trace.Allowances))
+	}
+	for i, expect := range expectAllowances {
+		got := trace.Allowances[i]
+		if got.AllowanceType != expect.AllowanceType || !got.Claimed.Equal(expect.Claimed) || got.Dependants != expect.Dependants ||
+			!got.Cap.Equal(expect.Cap) || !got.Allowed.Equal(expect.Allowed) {
+			t.Errorf("Expected %+v, got %+v", expect, got)
+		}
+	}
+
+	if !trace.TaxableIncome.Equal(decimal.NewFromInt(351000)) {
+		t.Errorf("Expected taxable income 351000, got %v", trace.TaxableIncome)
+	}
+
+	if !trace.Brackets[1].Income.Equal(decimal.NewFromInt(201000)) || !trace.Brackets[1].Tax.Equal(decimal.NewFromInt(20100)) {
+		t.Errorf("Expected 20100 tax on 201000, got %+v", trace.Brackets[1])
+	}
+
+	if !trace.Tax.Equal(decimal.NewFromInt(20100)) || !trace.TaxPayable.IsZero() || !trace.TaxRefund.Equal(decimal.NewFromInt(4900)) {
+		t.Errorf("Unexpected result %v tax, %v payable, %v refund", trace.Tax, trace.TaxPayable, trace.TaxRefund)
+	}
+}
+
+func TestCalculateTraceMinimumTax(t *testing.T) {
+	req := CalculationRequest{
+		Incomes: []Income{
+			{Category: "40(8)", Amount: decimal.NewFromInt(2000000), ExpenseMethod: ExpenseActual, ActualExpenses: decimal.NewFromInt(1900000)},
+		},
+	}
+
+	trace := Calculate(defaultTaxBrackets, defaultDeductions, req).Trace
+
+	if trace.TaxMethods == nil {
+		t.Fatalf("Expected the tax methods to be traced")
+	}
+
+	if trace.TaxMethods.Method != MethodMinimum || !trace.TaxMethods.MinimumTax.Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("Expected the minimum tax of 10000 to win, got %+v", trace.TaxMethods)
+	}
+
+	if !trace.ProgressiveTax.IsZero() || !trace.Tax.Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("Expected 0 progressive tax and 10000 tax, got %v and %v", trace.ProgressiveTax, trace.Tax)
+	}
+}
@@ -0,0 +1,119 @@
+package tax
+
+import (
+	"github.com/danyouknowme/assessment-tax/db"
+	"github.com/shopspring/decimal"
+)
+
+const monthsInYear = 12
+
+// WithholdingRequest describes a year of payroll: the same salary every
+// month, bonuses paid in given months and the allowances the employee
+// claims. Salary and bonuses are 40(1) income.
+type WithholdingRequest struct {
+	MonthlySalary decimal.Decimal `json:"monthlySalary" validate:"required,gt=0"`
+	Bonuses       []Bonus         `json:"bonuses" validate:"dive"`
+	Allowances    []Allowance     `json:"allowances" validate:"dive"`
+	Rounding      Rounding        `json:"rounding,omitempty" validate:"omitempty,oneof=total bracket"`
+	TaxYear       int             `json:"taxYear,omitempty" validate:"omitempty,min=1900,max=2100"`
+	AsOf          Date            `json:"asOf"`
+}
+
+// Bonus is paid on top of the salary in Month, from 1 for January to 12.
+type Bonus struct {
+	Month  int             `json:"month" validate:"min=1,max=12"`
+	Amount decimal.Decimal `json:"amount" validate:"min=0.0"`
+}
+
+// BonusIn returns the bonuses paid in month.
+func (r WithholdingRequest) BonusIn(month int) decimal.Decimal {
+	bonus := decimal.Zero
+	for _, b := range r.Bonuses {
+		if b.Month == month {
+			bonus = bonus.Add(b.Amount)
+		}
+	}
+
+	return bonus
+}
+
+// CalculationRequest returns the calculation of a year of salary plus
+// bonuses.
+func (r WithholdingRequest) CalculationRequest(bonuses decimal.Decimal) CalculationRequest {
+	return CalculationRequest{
+		Incomes: []Income{
+			{Category: "40(1)", Amount: r.MonthlySalary.Mul(decimal.NewFromInt(monthsInYear)).Add(bonuses)},
+		},
+		Allowances: r.Allowances,
+		Rounding:   r.Rounding,
+		TaxYear:    r.TaxYear,
+		AsOf:       r.AsOf,
+	}
+}
+
+// MonthlyWithholding is the tax withheld from the pay of one month.
+// ProjectedIncome and ProjectedTax are the annual income and tax projected
+// from the salary and the bonuses paid so far.
+type MonthlyWithholding struct {
+	Month                 int             `json:"month"`
+	Salary                decimal.Decimal `json:"salary"`
+	Bonus                 decimal.Decimal `json:"bonus"`
+	ProjectedIncome       decimal.Decimal `json:"projectedIncome"`
+	ProjectedTax          decimal.Decimal `json:"projectedTax"`
+	Withholding           decimal.Decimal `json:"withholding"`
+	CumulativeWithholding decimal.Decimal `json:"cumulativeWithholding"`
+}
+
+// WithholdingSchedule spreads the annual tax over the twelve months of
+// pay; the withholdings add up to AnnualTax.
+type WithholdingSchedule struct {
+	AnnualIncome decimal.Decimal      `json:"annualIncome"`
+	AnnualTax    decimal.Decimal      `json:"annualTax"`
+	Months       []MonthlyWithholding `json:"months"`
+}
+
+// ScheduleWithholding works out the monthly withholding the way payroll
+// files it: the tax on a year of salary is withheld in twelve equal parts,
+// and each bonus is withheld in full in the month it is paid as the
+// increase of the projected annual tax. December absorbs the rounding.
+func ScheduleWithholding(taxBrackets []db.TaxBracket, defaultDeductions []db.Deduction, req WithholdingRequest) WithholdingSchedule {
+	annualTax := func(bonuses decimal.Decimal) decimal.Decimal {
+		return Calculate(taxBrackets, defaultDeductions, req.CalculationRequest(bonuses)).Tax
+	}
+
+	salaryTax := annualTax(decimal.Zero)
+	base := roundMoney(salaryTax.Div(decimal.NewFromInt(monthsInYear)))
+
+	schedule := WithholdingSchedule{Months: []MonthlyWithholding{}}
+	bonuses, projectedTax, withheld := decimal.Zero, salaryTax, decimal.Zero
+	for month := 1; month <= monthsInYear; month++ {
+		bonus := req.BonusIn(month)
+
+		withholding := base
+		if bonus.IsPositive() {
+			bonuses = bonuses.Add(bonus)
+			previousTax := projectedTax
+			projectedTax = annualTax(bonuses)
+			withholding = withholding.Add(projectedTax.Sub(previousTax))
+		}
+		if month == monthsInYear {
+			withholding = projectedTax.Sub(withheld)
+		}
+		withheld = withheld.Add(withholding)
+
+		schedule.Months = append(schedule.Months, MonthlyWithholding{
+			Month:                 month,
+			Salary:                req.MonthlySalary,
+			Bonus:                 bonus,
+			ProjectedIncome:       req.CalculationRequest(bonuses).GrossIncome(),
+			ProjectedTax:          projectedTax,
+			Withholding:           withholding,
+			CumulativeWithholding: withheld,
+		})
+	}
+
+	schedule.AnnualIncome = req.CalculationRequest(bonuses).GrossIncome()
+	schedule.AnnualTax = projectedTax
+
+	return schedule
+}
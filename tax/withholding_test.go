@@ -0,0 +1,84 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestScheduleWithholding(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             WithholdingRequest
+		expectAnnualTax   decimal.Decimal
+		expectWithholding map[int]decimal.Decimal
+	}{
+		{
+			name:            "Salary only should be withheld evenly with December absorbing the rounding",
+			input:           WithholdingRequest{MonthlySalary: decimal.NewFromInt(50000)},
+			expectAnnualTax: decimal.NewFromInt(29000),
+			expectWithholding: map[int]decimal.Decimal{
+				1:  decimal.RequireFromString("2416.67"),
+				6:  decimal.RequireFromString("2416.67"),
+				12: decimal.RequireFromString("2416.63"),
+			},
+		},
+		{
+			name: "Bonus mid-year should be withheld in full in its month",
+			input: WithholdingRequest{
+				MonthlySalary: decimal.NewFromInt(50000),
+				Bonuses: []Bonus{
+					{Month: 6, Amount: decimal.NewFromInt(100000)},
+				},
+			},
+			expectAnnualTax: decimal.NewFromInt(41000),
+			expectWithholding: map[int]decimal.Decimal{
+				5:  decimal.RequireFromString("2416.67"),
+				6:  decimal.RequireFromString("14416.67"),
+				7:  decimal.RequireFromString("2416.67"),
+				12: decimal.RequireFromString("2416.63"),
+			},
+		},
+		{
+			name: "Bonus lifting a tax-free salary into tax should be withheld in its month only",
+			input: WithholdingRequest{
+				MonthlySalary: decimal.NewFromInt(20000),
+				Bonuses: []Bonus{
+					{Month: 12, Amount: decimal.NewFromInt(200000)},
+				},
+			},
+			expectAnnualTax: decimal.NewFromInt(13000),
+			expectWithholding: map[int]decimal.Decimal{
+				1:  decimal.Zero,
+				11: decimal.Zero,
+				12: decimal.NewFromInt(13000),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := ScheduleWithholding(defaultTaxBrackets, defaultDeductions, tc.input)
+
+			if !schedule.AnnualTax.Equal(tc.expectAnnualTax) {
+				t.Errorf("Expected annual tax %v, got %v", tc.expectAnnualTax, schedule.AnnualTax)
+			}
+
+			if len(schedule.Months) != monthsInYear {
+				t.Fatalf("Expected %d months, got %d", monthsInYear, len(schedule.Months))
+			}
+
+			withheld := decimal.Zero
+			for _, month := range schedule.Months {
+				withheld = withheld.Add(month.Withholding)
+				if expected, ok := tc.expectWithholding[month.Month]; ok && !month.Withholding.Equal(expected) {
+					t.Errorf("Expected withholding %v in month %d, got %v", expected, month.Month, month.Withholding)
+				}
+			}
+
+			if !withheld.Equal(tc.expectAnnualTax) {
+				t.Errorf("Expected withholdings to add up to %v, got %v", tc.expectAnnualTax, withheld)
+			}
+		})
+	}
+}